@@ -0,0 +1,137 @@
+package main
+
+import (
+	"cpe/calendar/config"
+	"cpe/calendar/logger"
+	"cpe/calendar/request"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// commands are the subcommands run instead of the server
+var commands = map[string]func(args []string, out io.Writer) error{
+	"history": runHistory,
+	"diff":    runDiff,
+}
+
+// runCommand runs a subcommand with the configuration of the server. Logs
+// go to stderr so the output of the command can be piped.
+func runCommand(name string, args []string) error {
+	command, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q (available: history, diff)", name)
+	}
+	logger.Log = logger.Log.Output(os.Stderr)
+
+	return command(args, os.Stdout)
+}
+
+// commandFlags parses the flags shared by the subcommands and configures the
+// request package
+func commandFlags(name string, args []string) (*flag.FlagSet, bool, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML configuration file")
+	dataDir := fs.String("data-dir", "", "persistent state directory")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	// Load the configuration the same way as the server
+	var loadArgs []string
+	if *configFile != "" {
+		loadArgs = append(loadArgs, "-config", *configFile)
+	}
+	if *dataDir != "" {
+		loadArgs = append(loadArgs, "-data-dir", *dataDir)
+	}
+	cfg, err := config.Load(loadArgs)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := request.Configure(cfg); err != nil {
+		return nil, false, err
+	}
+
+	return fs, *asJSON, nil
+}
+
+// runHistory lists the archived snapshots
+func runHistory(args []string, out io.Writer) error {
+	_, asJSON, err := commandFlags("history", args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := request.ListArchive()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(out).Encode(entries)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFETCHED AT\tSIZE")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", entry.ID, entry.FetchedAt.Format("2006-01-02 15:04:05 MST"), entry.Size)
+	}
+	return tw.Flush()
+}
+
+// runDiff prints the changes between two archived snapshots:
+// diff [from [to]], defaulting to the last two
+func runDiff(args []string, out io.Writer) error {
+	fs, asJSON, err := commandFlags("diff", args)
+	if err != nil {
+		return err
+	}
+
+	var from, to string
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		from = fs.Arg(0)
+	case 2:
+		from, to = fs.Arg(0), fs.Arg(1)
+	default:
+		return fmt.Errorf("usage: diff [-config file] [-data-dir dir] [-json] [from [to]]")
+	}
+
+	diff, err := request.DiffArchived(from, to)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return json.NewEncoder(out).Encode(diff)
+	}
+
+	fmt.Fprintf(out, "Changes from %s to %s\n", diff.From.ID, diff.To.ID)
+	if diff.Empty() {
+		fmt.Fprintln(out, "No changes")
+		return nil
+	}
+	for _, race := range diff.Added {
+		fmt.Fprintf(out, "+ %s (%s)\n", raceTitle(race.Name, race.Stage), race.StartDate)
+	}
+	for _, race := range diff.Removed {
+		fmt.Fprintf(out, "- %s (%s)\n", raceTitle(race.Name, race.Stage), race.StartDate)
+	}
+	for _, change := range diff.Modified {
+		fmt.Fprintf(out, "~ %s\n", raceTitle(change.Name, change.Stage))
+		for _, field := range change.Changes {
+			fmt.Fprintf(out, "    %s: %s -> %s\n", field.Field, field.Old, field.New)
+		}
+	}
+	return nil
+}
+
+// raceTitle joins the name and stage of a race
+func raceTitle(name, stage string) string {
+	return strings.TrimSpace(name + " " + stage)
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"cpe/calendar/logger"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the runtime configuration of the service
+type Config struct {
+	Addr               string        `yaml:"addr"`                // Listen address of the HTTP server
+	UpstreamURL        string        `yaml:"upstream_url"`        // Tiz schedule URL
+	UserAgent          string        `yaml:"user_agent"`          // User-Agent sent to the upstream
+	CacheTTL           time.Duration `yaml:"cache_ttl"`           // How long a snapshot is considered fresh
+	RefreshAhead       time.Duration `yaml:"refresh_ahead"`       // How long before expiry the refresher fetches
+	HTTPTimeout        time.Duration `yaml:"http_timeout"`        // Timeout of a single upstream fetch
+	DataDir            string        `yaml:"data_dir"`            // Persistent state directory, empty disables persistence
+	Sources            []string      `yaml:"sources"`             // Active race source names
+	Timezone           string        `yaml:"timezone"`            // IANA timezone advertised in the calendar
+	CalendarName       string        `yaml:"calendar_name"`       // Name of the generated calendar
+	RefreshInterval    time.Duration `yaml:"refresh_interval"`    // REFRESH-INTERVAL suggested to calendar clients
+	DebugToken         string        `yaml:"debug_token"`         // Bearer token of the /debug endpoints, empty disables them
+	ReplayPath         string        `yaml:"replay_path"`         // Captured feed or directory of feeds read by the file source
+	UCIClassifications string        `yaml:"uci_classifications"` // Dataset of UCI race classes replacing the bundled one
+	OverridesPath      string        `yaml:"overrides_path"`      // YAML or JSON file patching, hiding and adding races
+	CancelledRetention time.Duration `yaml:"cancelled_retention"` // How long a cancelled race is served once the feed drops it
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Addr:               ":8080",
+		UpstreamURL:        "https://cyclingtiz.live/sys-parse.php?file=db/races.txt",
+		UserAgent:          "cycling-calendar/1.0 (+https://github.com/loan-mgt/cycling-calendar)",
+		CacheTTL:           24 * time.Hour,
+		RefreshAhead:       1 * time.Hour,
+		HTTPTimeout:        30 * time.Second,
+		DataDir:            "data",
+		Sources:            []string{"tiz"},
+		Timezone:           "UTC",
+		CalendarName:       "Cycling Calendar",
+		RefreshInterval:    1 * time.Hour,
+		CancelledRetention: 7 * 24 * time.Hour,
+	}
+}
+
+// Load builds the configuration from, in increasing priority, the defaults,
+// the optional YAML file (CONFIG_FILE or -config), the environment and the
+// command line flags
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("cycling-calendar", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML configuration file")
+	addr := fs.String("addr", "", "listen address (e.g. :8080)")
+	upstreamURL := fs.String("upstream-url", "", "Tiz schedule URL")
+	cacheTTL := fs.Duration("cache-ttl", 0, "how long a snapshot is considered fresh")
+	httpTimeout := fs.Duration("http-timeout", 0, "timeout of a single upstream fetch")
+	dataDir := fs.String("data-dir", "", "persistent state directory")
+	sources := fs.String("sources", "", "comma separated race sources")
+	timezone := fs.String("timezone", "", "IANA timezone advertised in the calendar")
+	replayPath := fs.String("replay", "", "captured feed, or directory of feeds, read by the file source")
+	overridesPath := fs.String("overrides", "", "YAML or JSON file patching, hiding and adding races")
+	uciClassifications := fs.String("uci-classifications", "", "dataset of UCI race classes replacing the bundled one")
+	cancelledRetention := fs.Duration("cancelled-retention", 0, "how long a cancelled race is served once the feed drops it")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return cfg, err
+	}
+
+	// Flags only override what was explicitly set
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Addr = *addr
+		case "upstream-url":
+			cfg.UpstreamURL = *upstreamURL
+		case "cache-ttl":
+			cfg.CacheTTL = *cacheTTL
+		case "http-timeout":
+			cfg.HTTPTimeout = *httpTimeout
+		case "data-dir":
+			cfg.DataDir = *dataDir
+		case "sources":
+			cfg.Sources = splitList(*sources)
+		case "timezone":
+			cfg.Timezone = *timezone
+		case "replay":
+			cfg.ReplayPath = *replayPath
+		case "uci-classifications":
+			cfg.UCIClassifications = *uciClassifications
+		case "overrides":
+			cfg.OverridesPath = *overridesPath
+		case "cancelled-retention":
+			cfg.CancelledRetention = *cancelledRetention
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+// loadFile applies the settings of a YAML configuration file
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadEnv applies the settings found in environment variables
+func (c *Config) loadEnv() error {
+	strs := map[string]*string{
+		"ADDR":                &c.Addr,
+		"TIZ_URL":             &c.UpstreamURL,
+		"USER_AGENT":          &c.UserAgent,
+		"DATA_DIR":            &c.DataDir,
+		"TIMEZONE":            &c.Timezone,
+		"CALENDAR_NAME":       &c.CalendarName,
+		"DEBUG_TOKEN":         &c.DebugToken,
+		"REPLAY_PATH":         &c.ReplayPath,
+		"UCI_CLASSIFICATIONS": &c.UCIClassifications,
+		"OVERRIDES_PATH":      &c.OverridesPath,
+	}
+	for key, field := range strs {
+		if value, ok := os.LookupEnv(key); ok {
+			*field = value
+		}
+	}
+
+	// PORT is kept as a shorthand for ADDR
+	if port := os.Getenv("PORT"); port != "" && os.Getenv("ADDR") == "" {
+		c.Addr = ":" + port
+	}
+
+	durations := map[string]*time.Duration{
+		"CACHE_TTL":           &c.CacheTTL,
+		"REFRESH_AHEAD":       &c.RefreshAhead,
+		"HTTP_TIMEOUT":        &c.HTTPTimeout,
+		"REFRESH_INTERVAL":    &c.RefreshInterval,
+		"CANCELLED_RETENTION": &c.CancelledRetention,
+	}
+	for key, field := range durations {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*field = d
+	}
+
+	if value := os.Getenv("RACE_SOURCES"); value != "" {
+		c.Sources = splitList(value)
+	}
+
+	return nil
+}
+
+// Validate checks that every setting is usable
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Addr == "" {
+		errs = append(errs, errors.New("addr must not be empty"))
+	}
+	if u, err := url.Parse(c.UpstreamURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("upstream_url must be an absolute http(s) URL, got %q", c.UpstreamURL))
+	}
+	if c.UserAgent == "" {
+		errs = append(errs, errors.New("user_agent must not be empty"))
+	}
+	if c.CacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("cache_ttl must be positive, got %s", c.CacheTTL))
+	}
+	if c.RefreshAhead < 0 || c.RefreshAhead >= c.CacheTTL {
+		errs = append(errs, fmt.Errorf("refresh_ahead must be between 0 and cache_ttl, got %s", c.RefreshAhead))
+	}
+	if c.HTTPTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("http_timeout must be positive, got %s", c.HTTPTimeout))
+	}
+	if len(c.Sources) == 0 {
+		errs = append(errs, errors.New("at least one race source is required"))
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("timezone must be an IANA timezone, got %q", c.Timezone))
+	}
+	if c.CalendarName == "" {
+		errs = append(errs, errors.New("calendar_name must not be empty"))
+	}
+	if c.DebugToken != "" && len(c.DebugToken) < 16 {
+		errs = append(errs, errors.New("debug_token must be at least 16 characters"))
+	}
+	replaySource := false
+	for _, source := range c.Sources {
+		replaySource = replaySource || source == "file"
+	}
+	if replaySource && c.ReplayPath == "" {
+		errs = append(errs, errors.New("replay_path is required by the file source"))
+	}
+	if !replaySource && c.ReplayPath != "" {
+		errs = append(errs, errors.New("replay_path is set but the file source is not selected"))
+	}
+	if c.RefreshInterval < time.Minute {
+		errs = append(errs, fmt.Errorf("refresh_interval must be at least 1m, got %s", c.RefreshInterval))
+	}
+	if c.CancelledRetention < 0 {
+		errs = append(errs, fmt.Errorf("cancelled_retention must not be negative, got %s", c.CancelledRetention))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Log writes the effective configuration to the logger
+func (c Config) Log() {
+	logger.Log.Info().
+		Str("addr", c.Addr).
+		Str("upstreamURL", c.UpstreamURL).
+		Str("userAgent", c.UserAgent).
+		Dur("cacheTTL", c.CacheTTL).
+		Dur("refreshAhead", c.RefreshAhead).
+		Dur("httpTimeout", c.HTTPTimeout).
+		Str("dataDir", c.DataDir).
+		Strs("sources", c.Sources).
+		Str("timezone", c.Timezone).
+		Str("calendarName", c.CalendarName).
+		Dur("refreshInterval", c.RefreshInterval).
+		Bool("debugEndpoints", c.DebugToken != "").
+		Str("replayPath", c.ReplayPath).
+		Str("uciClassifications", c.UCIClassifications).
+		Str("overridesPath", c.OverridesPath).
+		Dur("cancelledRetention", c.CancelledRetention).
+		Msg("Effective configuration")
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := []byte("addr: \":9000\"\ncache_ttl: 12h\nsources: [tiz]\ncalendar_name: From file\n")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("CACHE_TTL", "6h")
+	t.Setenv("TIMEZONE", "Europe/Paris")
+
+	cfg, err := Load([]string{"-addr", ":9100"})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.Addr != ":9100" {
+		t.Errorf("Expected flag to override file, got addr %s", cfg.Addr)
+	}
+	if cfg.CacheTTL != 6*time.Hour {
+		t.Errorf("Expected env to override file, got cache_ttl %s", cfg.CacheTTL)
+	}
+	if cfg.CalendarName != "From file" {
+		t.Errorf("Expected file value, got calendar_name %s", cfg.CalendarName)
+	}
+	if cfg.Timezone != "Europe/Paris" {
+		t.Errorf("Expected env timezone, got %s", cfg.Timezone)
+	}
+	if cfg.HTTPTimeout != Default().HTTPTimeout {
+		t.Errorf("Expected default http_timeout, got %s", cfg.HTTPTimeout)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"relative upstream", func(c *Config) { c.UpstreamURL = "/races.txt" }},
+		{"zero ttl", func(c *Config) { c.CacheTTL = 0 }},
+		{"refresh ahead beyond ttl", func(c *Config) { c.RefreshAhead = c.CacheTTL }},
+		{"negative timeout", func(c *Config) { c.HTTPTimeout = -time.Second }},
+		{"no sources", func(c *Config) { c.Sources = nil }},
+		{"unknown timezone", func(c *Config) { c.Timezone = "fr" }},
+		{"tiny refresh interval", func(c *Config) { c.RefreshInterval = time.Second }},
+		{"negative cancelled retention", func(c *Config) { c.CancelledRetention = -time.Hour }},
+		{"short debug token", func(c *Config) { c.DebugToken = "secret" }},
+		{"file source without replay path", func(c *Config) { c.Sources = []string{"file"} }},
+		{"replay path without file source", func(c *Config) { c.ReplayPath = "testdata" }},
+	}
+
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default configuration is invalid: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("Expected a validation error")
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("cache_tll: 1h\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load([]string{"-config", path}); err == nil {
+		t.Error("Expected an error for a misspelled key")
+	}
+}
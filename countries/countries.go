@@ -0,0 +1,57 @@
+package countries
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Country is an ISO 3166-1 country
+type Country struct {
+	Alpha2    string  // FR
+	Alpha3    string  // FRA
+	Name      string  // France
+	Latitude  float64 // Of the geographic centre, in degrees
+	Longitude float64
+}
+
+var (
+	// iocCodes maps the IOC codes used by sports federations that differ
+	// from the ISO 3166 alpha-3 code of the country
+	iocCodes = map[string]string{
+		"ALG": "DZ", "ANG": "AO", "BAH": "BS", "BOT": "BW", "BUL": "BG", "CHI": "CL",
+		"CRC": "CR", "CRO": "HR", "DEN": "DK", "ESA": "SV", "GER": "DE", "GRE": "GR",
+		"GUA": "GT", "HON": "HN", "INA": "ID", "IRI": "IR", "KSA": "SA", "KUW": "KW",
+		"LAT": "LV", "MAS": "MY", "MGL": "MN", "NCA": "NI", "NED": "NL", "NGR": "NG",
+		"NIG": "NE", "OMA": "OM", "PAR": "PY", "PHI": "PH", "POR": "PT", "PUR": "PR",
+		"RSA": "ZA", "SLO": "SI", "SRI": "LK", "SUI": "CH", "TPE": "TW", "UAE": "AE",
+		"URU": "UY", "VIE": "VN", "ZIM": "ZW",
+	}
+
+	// byCode indexes the table by alpha-2, alpha-3 and IOC code
+	byCode = func() map[string]Country {
+		index := make(map[string]Country, 3*len(table))
+		for _, country := range table {
+			index[country.Alpha2] = country
+			index[country.Alpha3] = country
+		}
+		for ioc, alpha2 := range iocCodes {
+			index[ioc] = index[alpha2]
+		}
+		// Used for the United Kingdom by the EU and many flag sites
+		index["UK"] = index["GB"]
+		return index
+	}()
+)
+
+// Lookup returns the country with the given alpha-2, alpha-3 or IOC code,
+// in any case
+func Lookup(code string) (Country, bool) {
+	country, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return country, ok
+}
+
+// Geo formats the centre of the country as an iCalendar GEO value
+// (latitude;longitude, RFC 5545 3.8.1.6)
+func (c Country) Geo() string {
+	return fmt.Sprintf("%.2f;%.2f", c.Latitude, c.Longitude)
+}
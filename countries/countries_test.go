@@ -0,0 +1,52 @@
+package countries
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code   string
+		alpha2 string
+		name   string
+	}{
+		{"FR", "FR", "France"},
+		{"fr", "FR", "France"},
+		{"ESP", "ES", "Spain"},
+		{"are", "AE", "United Arab Emirates"},
+		{"SLO", "SI", "Slovenia"},
+		{"NED", "NL", "Netherlands"},
+		{"UK", "GB", "United Kingdom"},
+		{"XK", "XK", "Kosovo"},
+	}
+
+	for _, tt := range tests {
+		country, ok := Lookup(tt.code)
+		if !ok || country.Alpha2 != tt.alpha2 || country.Name != tt.name {
+			t.Errorf("Lookup(%q): expected %s %s, got %+v (found: %v)", tt.code, tt.alpha2, tt.name, country, ok)
+		}
+	}
+
+	for _, code := range []string{"", "B", "ZZ", "SLOV"} {
+		if country, ok := Lookup(code); ok {
+			t.Errorf("Lookup(%q): expected no country, got %+v", code, country)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	seen := map[string]bool{}
+	for _, country := range table {
+		if len(country.Alpha2) != 2 || len(country.Alpha3) != 3 || country.Name == "" {
+			t.Errorf("Malformed country %+v", country)
+		}
+		if seen[country.Alpha2] || seen[country.Alpha3] {
+			t.Errorf("Duplicate country %+v", country)
+		}
+		seen[country.Alpha2], seen[country.Alpha3] = true, true
+		if country.Latitude < -90 || country.Latitude > 90 || country.Longitude < -180 || country.Longitude > 180 {
+			t.Errorf("Centre of %s out of range: %s", country.Name, country.Geo())
+		}
+	}
+	if len(table) != 250 {
+		t.Errorf("Expected the 249 ISO 3166-1 countries and Kosovo, got %d", len(table))
+	}
+}
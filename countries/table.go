@@ -0,0 +1,257 @@
+package countries
+
+// table lists the ISO 3166-1 countries with the approximate geographic
+// centre of their main territory. Kosovo is listed under the user-assigned
+// code XK used by flag sites and sports federations.
+var table = []Country{
+	{Alpha2: "AD", Alpha3: "AND", Name: "Andorra", Latitude: 42.55, Longitude: 1.6},
+	{Alpha2: "AE", Alpha3: "ARE", Name: "United Arab Emirates", Latitude: 23.4, Longitude: 53.8},
+	{Alpha2: "AF", Alpha3: "AFG", Name: "Afghanistan", Latitude: 33.9, Longitude: 67.7},
+	{Alpha2: "AG", Alpha3: "ATG", Name: "Antigua and Barbuda", Latitude: 17.06, Longitude: -61.8},
+	{Alpha2: "AI", Alpha3: "AIA", Name: "Anguilla", Latitude: 18.22, Longitude: -63.05},
+	{Alpha2: "AL", Alpha3: "ALB", Name: "Albania", Latitude: 41.15, Longitude: 20.17},
+	{Alpha2: "AM", Alpha3: "ARM", Name: "Armenia", Latitude: 40.07, Longitude: 45.04},
+	{Alpha2: "AO", Alpha3: "AGO", Name: "Angola", Latitude: -12.3, Longitude: 17.5},
+	{Alpha2: "AQ", Alpha3: "ATA", Name: "Antarctica", Latitude: -75.25, Longitude: -0.07},
+	{Alpha2: "AR", Alpha3: "ARG", Name: "Argentina", Latitude: -38.4, Longitude: -63.6},
+	{Alpha2: "AS", Alpha3: "ASM", Name: "American Samoa", Latitude: -14.27, Longitude: -170.7},
+	{Alpha2: "AT", Alpha3: "AUT", Name: "Austria", Latitude: 47.52, Longitude: 14.55},
+	{Alpha2: "AU", Alpha3: "AUS", Name: "Australia", Latitude: -25.27, Longitude: 133.78},
+	{Alpha2: "AW", Alpha3: "ABW", Name: "Aruba", Latitude: 12.5, Longitude: -69.97},
+	{Alpha2: "AX", Alpha3: "ALA", Name: "Åland Islands", Latitude: 60.2, Longitude: 20},
+	{Alpha2: "AZ", Alpha3: "AZE", Name: "Azerbaijan", Latitude: 40.14, Longitude: 47.58},
+	{Alpha2: "BA", Alpha3: "BIH", Name: "Bosnia and Herzegovina", Latitude: 43.92, Longitude: 17.68},
+	{Alpha2: "BB", Alpha3: "BRB", Name: "Barbados", Latitude: 13.19, Longitude: -59.54},
+	{Alpha2: "BD", Alpha3: "BGD", Name: "Bangladesh", Latitude: 23.68, Longitude: 90.36},
+	{Alpha2: "BE", Alpha3: "BEL", Name: "Belgium", Latitude: 50.5, Longitude: 4.47},
+	{Alpha2: "BF", Alpha3: "BFA", Name: "Burkina Faso", Latitude: 12.24, Longitude: -1.56},
+	{Alpha2: "BG", Alpha3: "BGR", Name: "Bulgaria", Latitude: 42.73, Longitude: 25.49},
+	{Alpha2: "BH", Alpha3: "BHR", Name: "Bahrain", Latitude: 26.07, Longitude: 50.56},
+	{Alpha2: "BI", Alpha3: "BDI", Name: "Burundi", Latitude: -3.37, Longitude: 29.92},
+	{Alpha2: "BJ", Alpha3: "BEN", Name: "Benin", Latitude: 9.31, Longitude: 2.32},
+	{Alpha2: "BL", Alpha3: "BLM", Name: "Saint Barthélemy", Latitude: 17.9, Longitude: -62.83},
+	{Alpha2: "BM", Alpha3: "BMU", Name: "Bermuda", Latitude: 32.32, Longitude: -64.76},
+	{Alpha2: "BN", Alpha3: "BRN", Name: "Brunei", Latitude: 4.54, Longitude: 114.73},
+	{Alpha2: "BO", Alpha3: "BOL", Name: "Bolivia", Latitude: -16.29, Longitude: -63.59},
+	{Alpha2: "BQ", Alpha3: "BES", Name: "Caribbean Netherlands", Latitude: 12.18, Longitude: -68.26},
+	{Alpha2: "BR", Alpha3: "BRA", Name: "Brazil", Latitude: -14.24, Longitude: -51.93},
+	{Alpha2: "BS", Alpha3: "BHS", Name: "Bahamas", Latitude: 25.03, Longitude: -77.4},
+	{Alpha2: "BT", Alpha3: "BTN", Name: "Bhutan", Latitude: 27.51, Longitude: 90.43},
+	{Alpha2: "BV", Alpha3: "BVT", Name: "Bouvet Island", Latitude: -54.42, Longitude: 3.41},
+	{Alpha2: "BW", Alpha3: "BWA", Name: "Botswana", Latitude: -22.33, Longitude: 24.68},
+	{Alpha2: "BY", Alpha3: "BLR", Name: "Belarus", Latitude: 53.71, Longitude: 27.95},
+	{Alpha2: "BZ", Alpha3: "BLZ", Name: "Belize", Latitude: 17.19, Longitude: -88.5},
+	{Alpha2: "CA", Alpha3: "CAN", Name: "Canada", Latitude: 56.13, Longitude: -106.35},
+	{Alpha2: "CC", Alpha3: "CCK", Name: "Cocos Islands", Latitude: -12.16, Longitude: 96.87},
+	{Alpha2: "CD", Alpha3: "COD", Name: "Democratic Republic of the Congo", Latitude: -4.04, Longitude: 21.76},
+	{Alpha2: "CF", Alpha3: "CAF", Name: "Central African Republic", Latitude: 6.61, Longitude: 20.94},
+	{Alpha2: "CG", Alpha3: "COG", Name: "Congo", Latitude: -0.23, Longitude: 15.83},
+	{Alpha2: "CH", Alpha3: "CHE", Name: "Switzerland", Latitude: 46.82, Longitude: 8.23},
+	{Alpha2: "CI", Alpha3: "CIV", Name: "Côte d'Ivoire", Latitude: 7.54, Longitude: -5.55},
+	{Alpha2: "CK", Alpha3: "COK", Name: "Cook Islands", Latitude: -21.24, Longitude: -159.78},
+	{Alpha2: "CL", Alpha3: "CHL", Name: "Chile", Latitude: -35.68, Longitude: -71.54},
+	{Alpha2: "CM", Alpha3: "CMR", Name: "Cameroon", Latitude: 7.37, Longitude: 12.35},
+	{Alpha2: "CN", Alpha3: "CHN", Name: "China", Latitude: 35.86, Longitude: 104.2},
+	{Alpha2: "CO", Alpha3: "COL", Name: "Colombia", Latitude: 4.57, Longitude: -74.3},
+	{Alpha2: "CR", Alpha3: "CRI", Name: "Costa Rica", Latitude: 9.75, Longitude: -83.75},
+	{Alpha2: "CU", Alpha3: "CUB", Name: "Cuba", Latitude: 21.52, Longitude: -77.78},
+	{Alpha2: "CV", Alpha3: "CPV", Name: "Cabo Verde", Latitude: 16, Longitude: -24.01},
+	{Alpha2: "CW", Alpha3: "CUW", Name: "Curaçao", Latitude: 12.17, Longitude: -68.99},
+	{Alpha2: "CX", Alpha3: "CXR", Name: "Christmas Island", Latitude: -10.45, Longitude: 105.69},
+	{Alpha2: "CY", Alpha3: "CYP", Name: "Cyprus", Latitude: 35.13, Longitude: 33.43},
+	{Alpha2: "CZ", Alpha3: "CZE", Name: "Czechia", Latitude: 49.82, Longitude: 15.47},
+	{Alpha2: "DE", Alpha3: "DEU", Name: "Germany", Latitude: 51.17, Longitude: 10.45},
+	{Alpha2: "DJ", Alpha3: "DJI", Name: "Djibouti", Latitude: 11.83, Longitude: 42.59},
+	{Alpha2: "DK", Alpha3: "DNK", Name: "Denmark", Latitude: 56.26, Longitude: 9.5},
+	{Alpha2: "DM", Alpha3: "DMA", Name: "Dominica", Latitude: 15.41, Longitude: -61.37},
+	{Alpha2: "DO", Alpha3: "DOM", Name: "Dominican Republic", Latitude: 18.74, Longitude: -70.16},
+	{Alpha2: "DZ", Alpha3: "DZA", Name: "Algeria", Latitude: 28.03, Longitude: 1.66},
+	{Alpha2: "EC", Alpha3: "ECU", Name: "Ecuador", Latitude: -1.83, Longitude: -78.18},
+	{Alpha2: "EE", Alpha3: "EST", Name: "Estonia", Latitude: 58.6, Longitude: 25.01},
+	{Alpha2: "EG", Alpha3: "EGY", Name: "Egypt", Latitude: 26.82, Longitude: 30.8},
+	{Alpha2: "EH", Alpha3: "ESH", Name: "Western Sahara", Latitude: 24.22, Longitude: -12.89},
+	{Alpha2: "ER", Alpha3: "ERI", Name: "Eritrea", Latitude: 15.18, Longitude: 39.78},
+	{Alpha2: "ES", Alpha3: "ESP", Name: "Spain", Latitude: 40.46, Longitude: -3.75},
+	{Alpha2: "ET", Alpha3: "ETH", Name: "Ethiopia", Latitude: 9.15, Longitude: 40.49},
+	{Alpha2: "FI", Alpha3: "FIN", Name: "Finland", Latitude: 61.92, Longitude: 25.75},
+	{Alpha2: "FJ", Alpha3: "FJI", Name: "Fiji", Latitude: -16.58, Longitude: 179.41},
+	{Alpha2: "FK", Alpha3: "FLK", Name: "Falkland Islands", Latitude: -51.8, Longitude: -59.52},
+	{Alpha2: "FM", Alpha3: "FSM", Name: "Micronesia", Latitude: 7.43, Longitude: 150.55},
+	{Alpha2: "FO", Alpha3: "FRO", Name: "Faroe Islands", Latitude: 61.89, Longitude: -6.91},
+	{Alpha2: "FR", Alpha3: "FRA", Name: "France", Latitude: 46.23, Longitude: 2.21},
+	{Alpha2: "GA", Alpha3: "GAB", Name: "Gabon", Latitude: -0.8, Longitude: 11.61},
+	{Alpha2: "GB", Alpha3: "GBR", Name: "United Kingdom", Latitude: 55.38, Longitude: -3.44},
+	{Alpha2: "GD", Alpha3: "GRD", Name: "Grenada", Latitude: 12.26, Longitude: -61.6},
+	{Alpha2: "GE", Alpha3: "GEO", Name: "Georgia", Latitude: 42.32, Longitude: 43.36},
+	{Alpha2: "GF", Alpha3: "GUF", Name: "French Guiana", Latitude: 3.93, Longitude: -53.13},
+	{Alpha2: "GG", Alpha3: "GGY", Name: "Guernsey", Latitude: 49.47, Longitude: -2.59},
+	{Alpha2: "GH", Alpha3: "GHA", Name: "Ghana", Latitude: 7.95, Longitude: -1.02},
+	{Alpha2: "GI", Alpha3: "GIB", Name: "Gibraltar", Latitude: 36.14, Longitude: -5.35},
+	{Alpha2: "GL", Alpha3: "GRL", Name: "Greenland", Latitude: 71.71, Longitude: -42.6},
+	{Alpha2: "GM", Alpha3: "GMB", Name: "Gambia", Latitude: 13.44, Longitude: -15.31},
+	{Alpha2: "GN", Alpha3: "GIN", Name: "Guinea", Latitude: 9.95, Longitude: -9.7},
+	{Alpha2: "GP", Alpha3: "GLP", Name: "Guadeloupe", Latitude: 16.27, Longitude: -61.55},
+	{Alpha2: "GQ", Alpha3: "GNQ", Name: "Equatorial Guinea", Latitude: 1.65, Longitude: 10.27},
+	{Alpha2: "GR", Alpha3: "GRC", Name: "Greece", Latitude: 39.07, Longitude: 21.82},
+	{Alpha2: "GS", Alpha3: "SGS", Name: "South Georgia and the South Sandwich Islands", Latitude: -54.43, Longitude: -36.59},
+	{Alpha2: "GT", Alpha3: "GTM", Name: "Guatemala", Latitude: 15.78, Longitude: -90.23},
+	{Alpha2: "GU", Alpha3: "GUM", Name: "Guam", Latitude: 13.44, Longitude: 144.79},
+	{Alpha2: "GW", Alpha3: "GNB", Name: "Guinea-Bissau", Latitude: 11.8, Longitude: -15.18},
+	{Alpha2: "GY", Alpha3: "GUY", Name: "Guyana", Latitude: 4.86, Longitude: -58.93},
+	{Alpha2: "HK", Alpha3: "HKG", Name: "Hong Kong", Latitude: 22.4, Longitude: 114.11},
+	{Alpha2: "HM", Alpha3: "HMD", Name: "Heard Island and McDonald Islands", Latitude: -53.08, Longitude: 73.5},
+	{Alpha2: "HN", Alpha3: "HND", Name: "Honduras", Latitude: 15.2, Longitude: -86.24},
+	{Alpha2: "HR", Alpha3: "HRV", Name: "Croatia", Latitude: 45.1, Longitude: 15.2},
+	{Alpha2: "HT", Alpha3: "HTI", Name: "Haiti", Latitude: 18.97, Longitude: -72.29},
+	{Alpha2: "HU", Alpha3: "HUN", Name: "Hungary", Latitude: 47.16, Longitude: 19.5},
+	{Alpha2: "ID", Alpha3: "IDN", Name: "Indonesia", Latitude: -0.79, Longitude: 113.92},
+	{Alpha2: "IE", Alpha3: "IRL", Name: "Ireland", Latitude: 53.41, Longitude: -8.24},
+	{Alpha2: "IL", Alpha3: "ISR", Name: "Israel", Latitude: 31.05, Longitude: 34.85},
+	{Alpha2: "IM", Alpha3: "IMN", Name: "Isle of Man", Latitude: 54.24, Longitude: -4.55},
+	{Alpha2: "IN", Alpha3: "IND", Name: "India", Latitude: 20.59, Longitude: 78.96},
+	{Alpha2: "IO", Alpha3: "IOT", Name: "British Indian Ocean Territory", Latitude: -6.34, Longitude: 71.88},
+	{Alpha2: "IQ", Alpha3: "IRQ", Name: "Iraq", Latitude: 33.22, Longitude: 43.68},
+	{Alpha2: "IR", Alpha3: "IRN", Name: "Iran", Latitude: 32.43, Longitude: 53.69},
+	{Alpha2: "IS", Alpha3: "ISL", Name: "Iceland", Latitude: 64.96, Longitude: -19.02},
+	{Alpha2: "IT", Alpha3: "ITA", Name: "Italy", Latitude: 41.87, Longitude: 12.57},
+	{Alpha2: "JE", Alpha3: "JEY", Name: "Jersey", Latitude: 49.21, Longitude: -2.13},
+	{Alpha2: "JM", Alpha3: "JAM", Name: "Jamaica", Latitude: 18.11, Longitude: -77.3},
+	{Alpha2: "JO", Alpha3: "JOR", Name: "Jordan", Latitude: 30.59, Longitude: 36.24},
+	{Alpha2: "JP", Alpha3: "JPN", Name: "Japan", Latitude: 36.2, Longitude: 138.25},
+	{Alpha2: "KE", Alpha3: "KEN", Name: "Kenya", Latitude: -0.02, Longitude: 37.91},
+	{Alpha2: "KG", Alpha3: "KGZ", Name: "Kyrgyzstan", Latitude: 41.2, Longitude: 74.77},
+	{Alpha2: "KH", Alpha3: "KHM", Name: "Cambodia", Latitude: 12.57, Longitude: 104.99},
+	{Alpha2: "KI", Alpha3: "KIR", Name: "Kiribati", Latitude: -3.37, Longitude: -168.73},
+	{Alpha2: "KM", Alpha3: "COM", Name: "Comoros", Latitude: -11.88, Longitude: 43.87},
+	{Alpha2: "KN", Alpha3: "KNA", Name: "Saint Kitts and Nevis", Latitude: 17.36, Longitude: -62.78},
+	{Alpha2: "KP", Alpha3: "PRK", Name: "North Korea", Latitude: 40.34, Longitude: 127.51},
+	{Alpha2: "KR", Alpha3: "KOR", Name: "South Korea", Latitude: 35.91, Longitude: 127.77},
+	{Alpha2: "KW", Alpha3: "KWT", Name: "Kuwait", Latitude: 29.31, Longitude: 47.48},
+	{Alpha2: "KY", Alpha3: "CYM", Name: "Cayman Islands", Latitude: 19.51, Longitude: -80.57},
+	{Alpha2: "KZ", Alpha3: "KAZ", Name: "Kazakhstan", Latitude: 48.02, Longitude: 66.92},
+	{Alpha2: "LA", Alpha3: "LAO", Name: "Laos", Latitude: 19.86, Longitude: 102.5},
+	{Alpha2: "LB", Alpha3: "LBN", Name: "Lebanon", Latitude: 33.85, Longitude: 35.86},
+	{Alpha2: "LC", Alpha3: "LCA", Name: "Saint Lucia", Latitude: 13.91, Longitude: -60.98},
+	{Alpha2: "LI", Alpha3: "LIE", Name: "Liechtenstein", Latitude: 47.17, Longitude: 9.56},
+	{Alpha2: "LK", Alpha3: "LKA", Name: "Sri Lanka", Latitude: 7.87, Longitude: 80.77},
+	{Alpha2: "LR", Alpha3: "LBR", Name: "Liberia", Latitude: 6.43, Longitude: -9.43},
+	{Alpha2: "LS", Alpha3: "LSO", Name: "Lesotho", Latitude: -29.61, Longitude: 28.23},
+	{Alpha2: "LT", Alpha3: "LTU", Name: "Lithuania", Latitude: 55.17, Longitude: 23.88},
+	{Alpha2: "LU", Alpha3: "LUX", Name: "Luxembourg", Latitude: 49.82, Longitude: 6.13},
+	{Alpha2: "LV", Alpha3: "LVA", Name: "Latvia", Latitude: 56.88, Longitude: 24.6},
+	{Alpha2: "LY", Alpha3: "LBY", Name: "Libya", Latitude: 26.34, Longitude: 17.23},
+	{Alpha2: "MA", Alpha3: "MAR", Name: "Morocco", Latitude: 31.79, Longitude: -7.09},
+	{Alpha2: "MC", Alpha3: "MCO", Name: "Monaco", Latitude: 43.75, Longitude: 7.41},
+	{Alpha2: "MD", Alpha3: "MDA", Name: "Moldova", Latitude: 47.41, Longitude: 28.37},
+	{Alpha2: "ME", Alpha3: "MNE", Name: "Montenegro", Latitude: 42.71, Longitude: 19.37},
+	{Alpha2: "MF", Alpha3: "MAF", Name: "Saint Martin", Latitude: 18.08, Longitude: -63.05},
+	{Alpha2: "MG", Alpha3: "MDG", Name: "Madagascar", Latitude: -18.77, Longitude: 46.87},
+	{Alpha2: "MH", Alpha3: "MHL", Name: "Marshall Islands", Latitude: 7.13, Longitude: 171.18},
+	{Alpha2: "MK", Alpha3: "MKD", Name: "North Macedonia", Latitude: 41.61, Longitude: 21.75},
+	{Alpha2: "ML", Alpha3: "MLI", Name: "Mali", Latitude: 17.57, Longitude: -4},
+	{Alpha2: "MM", Alpha3: "MMR", Name: "Myanmar", Latitude: 21.91, Longitude: 95.96},
+	{Alpha2: "MN", Alpha3: "MNG", Name: "Mongolia", Latitude: 46.86, Longitude: 103.85},
+	{Alpha2: "MO", Alpha3: "MAC", Name: "Macao", Latitude: 22.2, Longitude: 113.54},
+	{Alpha2: "MP", Alpha3: "MNP", Name: "Northern Mariana Islands", Latitude: 17.33, Longitude: 145.38},
+	{Alpha2: "MQ", Alpha3: "MTQ", Name: "Martinique", Latitude: 14.64, Longitude: -61.02},
+	{Alpha2: "MR", Alpha3: "MRT", Name: "Mauritania", Latitude: 21.01, Longitude: -10.94},
+	{Alpha2: "MS", Alpha3: "MSR", Name: "Montserrat", Latitude: 16.74, Longitude: -62.19},
+	{Alpha2: "MT", Alpha3: "MLT", Name: "Malta", Latitude: 35.94, Longitude: 14.38},
+	{Alpha2: "MU", Alpha3: "MUS", Name: "Mauritius", Latitude: -20.35, Longitude: 57.55},
+	{Alpha2: "MV", Alpha3: "MDV", Name: "Maldives", Latitude: 3.2, Longitude: 73.22},
+	{Alpha2: "MW", Alpha3: "MWI", Name: "Malawi", Latitude: -13.25, Longitude: 34.3},
+	{Alpha2: "MX", Alpha3: "MEX", Name: "Mexico", Latitude: 23.63, Longitude: -102.55},
+	{Alpha2: "MY", Alpha3: "MYS", Name: "Malaysia", Latitude: 4.21, Longitude: 101.98},
+	{Alpha2: "MZ", Alpha3: "MOZ", Name: "Mozambique", Latitude: -18.67, Longitude: 35.53},
+	{Alpha2: "NA", Alpha3: "NAM", Name: "Namibia", Latitude: -22.96, Longitude: 18.49},
+	{Alpha2: "NC", Alpha3: "NCL", Name: "New Caledonia", Latitude: -20.9, Longitude: 165.62},
+	{Alpha2: "NE", Alpha3: "NER", Name: "Niger", Latitude: 17.61, Longitude: 8.08},
+	{Alpha2: "NF", Alpha3: "NFK", Name: "Norfolk Island", Latitude: -29.04, Longitude: 167.95},
+	{Alpha2: "NG", Alpha3: "NGA", Name: "Nigeria", Latitude: 9.08, Longitude: 8.68},
+	{Alpha2: "NI", Alpha3: "NIC", Name: "Nicaragua", Latitude: 12.87, Longitude: -85.21},
+	{Alpha2: "NL", Alpha3: "NLD", Name: "Netherlands", Latitude: 52.13, Longitude: 5.29},
+	{Alpha2: "NO", Alpha3: "NOR", Name: "Norway", Latitude: 60.47, Longitude: 8.47},
+	{Alpha2: "NP", Alpha3: "NPL", Name: "Nepal", Latitude: 28.39, Longitude: 84.12},
+	{Alpha2: "NR", Alpha3: "NRU", Name: "Nauru", Latitude: -0.52, Longitude: 166.93},
+	{Alpha2: "NU", Alpha3: "NIU", Name: "Niue", Latitude: -19.05, Longitude: -169.87},
+	{Alpha2: "NZ", Alpha3: "NZL", Name: "New Zealand", Latitude: -40.9, Longitude: 174.89},
+	{Alpha2: "OM", Alpha3: "OMN", Name: "Oman", Latitude: 21.51, Longitude: 55.92},
+	{Alpha2: "PA", Alpha3: "PAN", Name: "Panama", Latitude: 8.54, Longitude: -80.78},
+	{Alpha2: "PE", Alpha3: "PER", Name: "Peru", Latitude: -9.19, Longitude: -75.02},
+	{Alpha2: "PF", Alpha3: "PYF", Name: "French Polynesia", Latitude: -17.68, Longitude: -149.41},
+	{Alpha2: "PG", Alpha3: "PNG", Name: "Papua New Guinea", Latitude: -6.31, Longitude: 143.96},
+	{Alpha2: "PH", Alpha3: "PHL", Name: "Philippines", Latitude: 12.88, Longitude: 121.77},
+	{Alpha2: "PK", Alpha3: "PAK", Name: "Pakistan", Latitude: 30.38, Longitude: 69.35},
+	{Alpha2: "PL", Alpha3: "POL", Name: "Poland", Latitude: 51.92, Longitude: 19.15},
+	{Alpha2: "PM", Alpha3: "SPM", Name: "Saint Pierre and Miquelon", Latitude: 46.94, Longitude: -56.27},
+	{Alpha2: "PN", Alpha3: "PCN", Name: "Pitcairn Islands", Latitude: -24.7, Longitude: -127.44},
+	{Alpha2: "PR", Alpha3: "PRI", Name: "Puerto Rico", Latitude: 18.22, Longitude: -66.59},
+	{Alpha2: "PS", Alpha3: "PSE", Name: "Palestine", Latitude: 31.95, Longitude: 35.23},
+	{Alpha2: "PT", Alpha3: "PRT", Name: "Portugal", Latitude: 39.4, Longitude: -8.22},
+	{Alpha2: "PW", Alpha3: "PLW", Name: "Palau", Latitude: 7.51, Longitude: 134.58},
+	{Alpha2: "PY", Alpha3: "PRY", Name: "Paraguay", Latitude: -23.44, Longitude: -58.44},
+	{Alpha2: "QA", Alpha3: "QAT", Name: "Qatar", Latitude: 25.35, Longitude: 51.18},
+	{Alpha2: "RE", Alpha3: "REU", Name: "Réunion", Latitude: -21.12, Longitude: 55.54},
+	{Alpha2: "RO", Alpha3: "ROU", Name: "Romania", Latitude: 45.94, Longitude: 24.97},
+	{Alpha2: "RS", Alpha3: "SRB", Name: "Serbia", Latitude: 44.02, Longitude: 21.01},
+	{Alpha2: "RU", Alpha3: "RUS", Name: "Russia", Latitude: 61.52, Longitude: 105.32},
+	{Alpha2: "RW", Alpha3: "RWA", Name: "Rwanda", Latitude: -1.94, Longitude: 29.87},
+	{Alpha2: "SA", Alpha3: "SAU", Name: "Saudi Arabia", Latitude: 23.89, Longitude: 45.08},
+	{Alpha2: "SB", Alpha3: "SLB", Name: "Solomon Islands", Latitude: -9.65, Longitude: 160.16},
+	{Alpha2: "SC", Alpha3: "SYC", Name: "Seychelles", Latitude: -4.68, Longitude: 55.49},
+	{Alpha2: "SD", Alpha3: "SDN", Name: "Sudan", Latitude: 12.86, Longitude: 30.22},
+	{Alpha2: "SE", Alpha3: "SWE", Name: "Sweden", Latitude: 60.13, Longitude: 18.64},
+	{Alpha2: "SG", Alpha3: "SGP", Name: "Singapore", Latitude: 1.35, Longitude: 103.82},
+	{Alpha2: "SH", Alpha3: "SHN", Name: "Saint Helena", Latitude: -24.14, Longitude: -10.03},
+	{Alpha2: "SI", Alpha3: "SVN", Name: "Slovenia", Latitude: 46.15, Longitude: 14.99},
+	{Alpha2: "SJ", Alpha3: "SJM", Name: "Svalbard and Jan Mayen", Latitude: 77.55, Longitude: 23.67},
+	{Alpha2: "SK", Alpha3: "SVK", Name: "Slovakia", Latitude: 48.67, Longitude: 19.7},
+	{Alpha2: "SL", Alpha3: "SLE", Name: "Sierra Leone", Latitude: 8.46, Longitude: -11.78},
+	{Alpha2: "SM", Alpha3: "SMR", Name: "San Marino", Latitude: 43.94, Longitude: 12.46},
+	{Alpha2: "SN", Alpha3: "SEN", Name: "Senegal", Latitude: 14.5, Longitude: -14.45},
+	{Alpha2: "SO", Alpha3: "SOM", Name: "Somalia", Latitude: 5.15, Longitude: 46.2},
+	{Alpha2: "SR", Alpha3: "SUR", Name: "Suriname", Latitude: 3.92, Longitude: -56.03},
+	{Alpha2: "SS", Alpha3: "SSD", Name: "South Sudan", Latitude: 6.88, Longitude: 31.31},
+	{Alpha2: "ST", Alpha3: "STP", Name: "Sao Tome and Principe", Latitude: 0.19, Longitude: 6.61},
+	{Alpha2: "SV", Alpha3: "SLV", Name: "El Salvador", Latitude: 13.79, Longitude: -88.9},
+	{Alpha2: "SX", Alpha3: "SXM", Name: "Sint Maarten", Latitude: 18.04, Longitude: -63.07},
+	{Alpha2: "SY", Alpha3: "SYR", Name: "Syria", Latitude: 34.8, Longitude: 38.99},
+	{Alpha2: "SZ", Alpha3: "SWZ", Name: "Eswatini", Latitude: -26.52, Longitude: 31.47},
+	{Alpha2: "TC", Alpha3: "TCA", Name: "Turks and Caicos Islands", Latitude: 21.69, Longitude: -71.8},
+	{Alpha2: "TD", Alpha3: "TCD", Name: "Chad", Latitude: 15.45, Longitude: 18.73},
+	{Alpha2: "TF", Alpha3: "ATF", Name: "French Southern Territories", Latitude: -49.28, Longitude: 69.35},
+	{Alpha2: "TG", Alpha3: "TGO", Name: "Togo", Latitude: 8.62, Longitude: 0.82},
+	{Alpha2: "TH", Alpha3: "THA", Name: "Thailand", Latitude: 15.87, Longitude: 100.99},
+	{Alpha2: "TJ", Alpha3: "TJK", Name: "Tajikistan", Latitude: 38.86, Longitude: 71.28},
+	{Alpha2: "TK", Alpha3: "TKL", Name: "Tokelau", Latitude: -8.97, Longitude: -171.86},
+	{Alpha2: "TL", Alpha3: "TLS", Name: "Timor-Leste", Latitude: -8.87, Longitude: 125.73},
+	{Alpha2: "TM", Alpha3: "TKM", Name: "Turkmenistan", Latitude: 38.97, Longitude: 59.56},
+	{Alpha2: "TN", Alpha3: "TUN", Name: "Tunisia", Latitude: 33.89, Longitude: 9.54},
+	{Alpha2: "TO", Alpha3: "TON", Name: "Tonga", Latitude: -21.18, Longitude: -175.2},
+	{Alpha2: "TR", Alpha3: "TUR", Name: "Türkiye", Latitude: 38.96, Longitude: 35.24},
+	{Alpha2: "TT", Alpha3: "TTO", Name: "Trinidad and Tobago", Latitude: 10.69, Longitude: -61.22},
+	{Alpha2: "TV", Alpha3: "TUV", Name: "Tuvalu", Latitude: -7.11, Longitude: 177.65},
+	{Alpha2: "TW", Alpha3: "TWN", Name: "Taiwan", Latitude: 23.7, Longitude: 120.96},
+	{Alpha2: "TZ", Alpha3: "TZA", Name: "Tanzania", Latitude: -6.37, Longitude: 34.89},
+	{Alpha2: "UA", Alpha3: "UKR", Name: "Ukraine", Latitude: 48.38, Longitude: 31.17},
+	{Alpha2: "UG", Alpha3: "UGA", Name: "Uganda", Latitude: 1.37, Longitude: 32.29},
+	{Alpha2: "UM", Alpha3: "UMI", Name: "United States Minor Outlying Islands", Latitude: 19.28, Longitude: 166.65},
+	{Alpha2: "US", Alpha3: "USA", Name: "United States", Latitude: 37.09, Longitude: -95.71},
+	{Alpha2: "UY", Alpha3: "URY", Name: "Uruguay", Latitude: -32.52, Longitude: -55.77},
+	{Alpha2: "UZ", Alpha3: "UZB", Name: "Uzbekistan", Latitude: 41.38, Longitude: 64.59},
+	{Alpha2: "VA", Alpha3: "VAT", Name: "Vatican City", Latitude: 41.9, Longitude: 12.45},
+	{Alpha2: "VC", Alpha3: "VCT", Name: "Saint Vincent and the Grenadines", Latitude: 12.98, Longitude: -61.29},
+	{Alpha2: "VE", Alpha3: "VEN", Name: "Venezuela", Latitude: 6.42, Longitude: -66.59},
+	{Alpha2: "VG", Alpha3: "VGB", Name: "British Virgin Islands", Latitude: 18.42, Longitude: -64.64},
+	{Alpha2: "VI", Alpha3: "VIR", Name: "U.S. Virgin Islands", Latitude: 18.34, Longitude: -64.9},
+	{Alpha2: "VN", Alpha3: "VNM", Name: "Vietnam", Latitude: 14.06, Longitude: 108.28},
+	{Alpha2: "VU", Alpha3: "VUT", Name: "Vanuatu", Latitude: -15.38, Longitude: 166.96},
+	{Alpha2: "WF", Alpha3: "WLF", Name: "Wallis and Futuna", Latitude: -13.77, Longitude: -177.16},
+	{Alpha2: "WS", Alpha3: "WSM", Name: "Samoa", Latitude: -13.76, Longitude: -172.1},
+	{Alpha2: "XK", Alpha3: "XKX", Name: "Kosovo", Latitude: 42.6, Longitude: 20.9},
+	{Alpha2: "YE", Alpha3: "YEM", Name: "Yemen", Latitude: 15.55, Longitude: 48.52},
+	{Alpha2: "YT", Alpha3: "MYT", Name: "Mayotte", Latitude: -12.83, Longitude: 45.17},
+	{Alpha2: "ZA", Alpha3: "ZAF", Name: "South Africa", Latitude: -30.56, Longitude: 22.94},
+	{Alpha2: "ZM", Alpha3: "ZMB", Name: "Zambia", Latitude: -13.13, Longitude: 27.85},
+	{Alpha2: "ZW", Alpha3: "ZWE", Name: "Zimbabwe", Latitude: -19.02, Longitude: 29.15},
+}
@@ -0,0 +1,55 @@
+package dates
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout is the ISO 8601 date format used for race dates
+const Layout = "2006-01-02"
+
+// searchYears bounds how far after the reference year a date is looked up.
+// Four years always reach a leap year for 29th February.
+const searchYears = 4
+
+// pastWindow is how long before the reference a date still resolves to the
+// current year, for races that are ongoing or just finished
+const pastWindow = 30 * 24 * time.Hour
+
+// Resolve returns the first date with the given month and day on or after
+// ref, or at most pastWindow before it. The feed never prints years and
+// lists upcoming races, so "Friday 2nd January" read on 30th December is
+// next year, "15th August" read in February is this year and "30th
+// December" read on 2nd January is last year. A 29th February only
+// resolves to leap years. The boolean is false when the day does not exist
+// in that month.
+func Resolve(month time.Month, day int, ref time.Time) (time.Time, bool) {
+	earliest := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC).Add(-pastWindow)
+
+	for year := ref.Year() - 1; year <= ref.Year()+searchYears; year++ {
+		candidate := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		// time.Date normalises invalid days (30th February becomes March)
+		if candidate.Month() != month || candidate.Day() != day {
+			continue
+		}
+		if !candidate.Before(earliest) {
+			return candidate, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Parse parses an ISO 8601 race date (e.g. "2026-02-04") as a UTC midnight
+func Parse(value string) (time.Time, error) {
+	date, err := time.Parse(Layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: %s", value)
+	}
+	return date, nil
+}
+
+// Format formats a date as ISO 8601
+func Format(date time.Time) string {
+	return date.Format(Layout)
+}
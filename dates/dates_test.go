@@ -0,0 +1,60 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		month time.Month
+		day   int
+		ref   time.Time
+		want  string
+		ok    bool
+	}{
+		{"same day", time.February, 4, time.Date(2026, 2, 4, 12, 0, 0, 0, time.UTC), "2026-02-04", true},
+		{"later this year", time.June, 14, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "2026-06-14", true},
+		{"january seen in late december", time.January, 2, time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC), "2027-01-02", true},
+		{"new year seen on new year's eve", time.January, 1, time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC), "2027-01-01", true},
+		{"december seen in early january", time.December, 30, time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC), "2026-12-30", true},
+		{"ongoing race from last year", time.December, 28, time.Date(2027, 1, 5, 0, 0, 0, 0, time.UTC), "2026-12-28", true},
+		{"leap day in a leap year", time.February, 29, time.Date(2028, 2, 1, 0, 0, 0, 0, time.UTC), "2028-02-29", true},
+		{"leap day before the next leap year", time.February, 29, time.Date(2027, 12, 20, 0, 0, 0, 0, time.UTC), "2028-02-29", true},
+		{"leap day just after a leap year", time.February, 29, time.Date(2028, 3, 2, 0, 0, 0, 0, time.UTC), "2028-02-29", true},
+		{"leap day mid cycle", time.February, 29, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "2028-02-29", true},
+		{"more than six months ahead", time.August, 15, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "2026-08-15", true},
+		{"seven months ahead", time.September, 1, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "2026-09-01", true},
+		{"eleven months ahead", time.January, 1, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "2027-01-01", true},
+		{"just finished", time.January, 10, time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC), "2026-01-10", true},
+		{"past the window", time.January, 5, time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC), "2027-01-05", true},
+		{"invalid day", time.April, 31, time.Date(2026, 2, 4, 0, 0, 0, 0, time.UTC), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Resolve(tt.month, tt.day, tt.ref)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && Format(got) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, Format(got))
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	date, err := Parse("2028-02-29")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if date.Year() != 2028 || date.Month() != time.February || date.Day() != 29 {
+		t.Errorf("Expected 2028-02-29, got %s", date)
+	}
+
+	if _, err := Parse("29th February"); err == nil {
+		t.Error("Expected an error for a non ISO date")
+	}
+}
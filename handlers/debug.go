@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/request"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DebugParseHandler serves the report of the last parsed Tiz schedule
+func DebugParseHandler(w http.ResponseWriter, r *http.Request) {
+	if !authorizeDebug(w, r) {
+		return
+	}
+
+	report, ok := request.LatestParseReport()
+	if !ok {
+		http.Error(w, "No schedule parsed yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode parse report")
+	}
+}
+
+// authorizeDebug checks the bearer token of a /debug request, writing the
+// error response when it is refused. The endpoints do not exist when no
+// token is configured.
+func authorizeDebug(w http.ResponseWriter, r *http.Request) bool {
+	if debugToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(debugToken)) != 1 {
+		logger.Log.Warn().Str("path", r.URL.Path).Str("remoteAddr", r.RemoteAddr).Msg("Refused debug request")
+		w.Header().Set("WWW-Authenticate", `Bearer realm="debug"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
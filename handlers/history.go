@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/request"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// HistoryHandler lists the archived Tiz snapshots, oldest first
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := request.ListArchive()
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to list archived snapshots")
+		http.Error(w, "Failed to list snapshots", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []request.ArchiveEntry{}
+	}
+
+	writeJSON(w, entries)
+}
+
+// HistoryDiffHandler reports the races added, removed and modified between
+// two archived snapshots, given by the from and to query parameters. Without
+// them the latest snapshot is compared with the previous one.
+func HistoryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	diff, err := request.DiffArchived(query.Get("from"), query.Get("to"))
+	if errors.Is(err, request.ErrNoArchive) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to diff archived snapshots")
+		http.Error(w, "Failed to diff snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, diff)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
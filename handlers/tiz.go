@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"cpe/calendar/config"
 	"cpe/calendar/ical"
+	"cpe/calendar/languages"
 	"cpe/calendar/logger"
 	"cpe/calendar/request"
 	"cpe/calendar/types"
@@ -10,28 +12,34 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
-var allowedTizCategory = []string{
-	// Tiz cycling categories
-	"WE", "ME", "track", "MTB",
-	"NC", "JR", "WC",
-	"Women Elite", "Men Elite", "Women", "Men",
+var (
+	// calendarName is the name of the generated calendar
+	calendarName = "Cycling Calendar"
+	// debugToken is the bearer token of the /debug endpoints, empty disables them
+	debugToken = ""
+)
+
+// Configure applies the runtime configuration to the handlers package
+func Configure(cfg config.Config) {
+	calendarName = cfg.CalendarName
+	debugToken = cfg.DebugToken
 }
 
 // GenerateTizICSHandler generates ICS file and sends it in response
 func GenerateTizICSHandler(w http.ResponseWriter, r *http.Request) {
-	logger.Log.Info().Msg("Generating ICS from Tiz endpoint")
+	logger.Log.Info().Msg("Generating ICS from race sources")
 
 	filename := "cycling-calendar.ics"
-	calendarName := "Cycling Calendar"
 
-	// Fetch data from Tiz endpoint
-	tizRaces, err := request.GetTizRaces()
+	// Fetch data from every configured race source
+	tizRaces, warnings, err := request.GetRaces(r.Context())
 	if err != nil {
 		logger.Log.Error().
 			Err(err).
-			Msg("Failed to fetch Tiz data")
+			Msg("Failed to fetch race data")
 		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
 		return
 	}
@@ -46,7 +54,7 @@ func GenerateTizICSHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Check if request classes are in allowed list
 	for _, c := range requestClasses {
-		if !contains(allowedTizCategory, c) {
+		if !types.IsTizCategory(c) {
 			logger.Log.Error().
 				Str("class", c).
 				Msg("Class is not allowed")
@@ -55,8 +63,53 @@ func GenerateTizICSHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Filter Tiz races by categories
+	// Parse 'discipline' query parameters, any of them matches
+	var requestDisciplines []types.Discipline
+	for _, d := range r.URL.Query()["discipline"] {
+		discipline, ok := types.ParseDiscipline(d)
+		if !ok {
+			logger.Log.Error().
+				Str("discipline", d).
+				Msg("Discipline is not allowed")
+			http.Error(w, "Discipline is not allowed", http.StatusBadRequest)
+			return
+		}
+		requestDisciplines = append(requestDisciplines, discipline)
+	}
+
+	// Parse 'uci' query parameters, UCI classification tiers (WT, Pro, .1)
+	var requestTiers []string
+	for _, u := range r.URL.Query()["uci"] {
+		tier, ok := types.ParseClassificationTier(u)
+		if !ok {
+			logger.Log.Error().
+				Str("uci", u).
+				Msg("UCI classification is not allowed")
+			http.Error(w, "UCI classification is not allowed", http.StatusBadRequest)
+			return
+		}
+		requestTiers = append(requestTiers, tier)
+	}
+
+	// Parse 'lang' query parameters, BCP 47 tags or language names (en, es-419, Spanish)
+	var requestLanguages []string
+	for _, l := range r.URL.Query()["lang"] {
+		tag, ok := languages.Lookup(l)
+		if !ok {
+			logger.Log.Error().
+				Str("lang", l).
+				Msg("Language is not allowed")
+			http.Error(w, "Language is not allowed", http.StatusBadRequest)
+			return
+		}
+		requestLanguages = append(requestLanguages, tag)
+	}
+
+	// Filter Tiz races by categories, disciplines, UCI classification and commentary language
 	filteredRaces := filterTizRaces(tizRaces, requestClasses)
+	filteredRaces = filterDisciplines(filteredRaces, requestDisciplines)
+	filteredRaces = filterClassifications(filteredRaces, requestTiers)
+	filteredRaces = filterLanguages(filteredRaces, requestLanguages)
 
 	logger.Log.Info().
 		Int("filteredRacesCount", len(filteredRaces)).
@@ -66,9 +119,17 @@ func GenerateTizICSHandler(w http.ResponseWriter, r *http.Request) {
 	events := convertTizRacesToEvents(filteredRaces)
 
 	// Generate iCal file
-	icsContent := ical.GenerateTizICS(events, calendarName)
+	warning := strings.Join(warnings, "; ")
+	icsContent := ical.GenerateTizICS(events, calendarName, warning)
 
 	// Set headers and write content
+	if warning != "" {
+		logger.Log.Warn().Str("warning", warning).Msg("Serving degraded calendar")
+		w.Header().Set("Warning", fmt.Sprintf("110 - %q", strings.ReplaceAll(warning, "\"", "'")))
+	}
+	if age, ok := request.SnapshotAge(); ok {
+		w.Header().Set("X-Snapshot-Age", strconv.Itoa(int(age.Seconds())))
+	}
 	w.Header().Set("Content-Type", "text/calendar")
 	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
 	w.Write([]byte(icsContent))
@@ -90,6 +151,64 @@ func filterTizRaces(races []types.TizRace, categories []string) []types.TizRace
 	return filtered
 }
 
+// filterDisciplines keeps the races of the requested disciplines
+func filterDisciplines(races []types.TizRace, disciplines []types.Discipline) []types.TizRace {
+	if len(disciplines) == 0 {
+		return races
+	}
+
+	var filtered []types.TizRace
+	for _, race := range races {
+		for _, discipline := range disciplines {
+			if race.Discipline == discipline {
+				filtered = append(filtered, race)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// filterClassifications keeps the races of the requested UCI classification
+// tiers. Races without a known classification are dropped.
+func filterClassifications(races []types.TizRace, tiers []string) []types.TizRace {
+	if len(tiers) == 0 {
+		return races
+	}
+
+	var filtered []types.TizRace
+	for _, race := range races {
+		if tier, ok := types.ClassificationTier(race.Classification); ok && contains(tiers, tier) {
+			filtered = append(filtered, race)
+		}
+	}
+
+	return filtered
+}
+
+// filterLanguages keeps the races broadcast with commentary in any of the
+// requested languages, a tag matching its regional variants. Races without
+// a known commentary language are dropped.
+func filterLanguages(races []types.TizRace, tags []string) []types.TizRace {
+	if len(tags) == 0 {
+		return races
+	}
+
+	var filtered []types.TizRace
+	for _, race := range races {
+		raceLanguages := race.Languages()
+		for _, tag := range tags {
+			if languages.Matches(raceLanguages, tag) {
+				filtered = append(filtered, race)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
 // raceMatchesCategories checks if race has any of the requested categories
 func raceMatchesCategories(raceCategories []string, requestedCategories []string) bool {
 	for _, reqCat := range requestedCategories {
@@ -108,47 +227,57 @@ func convertTizRacesToEvents(tizRaces []types.TizRace) []types.Event {
 
 	for _, tizRace := range tizRaces {
 		event := types.Event{
-			Date:        tizRace.StartDate,
-			Title:       tizRace.Name,
-			Stage:       tizRace.Stage,
-			Country:     tizRace.Country,
-			CountryFlag: tizRace.CountryFlag,
-			StreamType:  tizRace.StreamType,
-			StreamLinks: tizRace.StreamLinks,
-			StreamLang:  tizRace.StreamLang,
-			Notes:       tizRace.Notes,
-			Categories:  tizRace.Categories,
-			StartDate:   tizRace.StartDate,
-			EndDate:     tizRace.EndDate,
-			Duration:    tizRace.Duration,
-			AllDay:      tizRace.AllDay,
-			Times:       tizRace.Times,
+			Date:           tizRace.StartDate,
+			Title:          tizRace.Name,
+			Stage:          tizRace.Stage,
+			Country:        tizRace.Country,
+			CountryName:    tizRace.CountryName,
+			CountryFlag:    tizRace.CountryFlag,
+			Status:         tizRace.Status,
+			StatusNote:     tizRace.StatusNote,
+			StreamType:     tizRace.StreamType,
+			StreamLinks:    tizRace.StreamLinks,
+			Links:          tizRace.Links,
+			StreamLang:     tizRace.StreamLang,
+			Notes:          tizRace.Notes,
+			Categories:     tizRace.Categories,
+			Discipline:     tizRace.Discipline,
+			Classification: tizRace.Classification,
+			StartDate:      tizRace.StartDate,
+			EndDate:        tizRace.EndDate,
+			DatePrecision:  tizRace.DatePrecision,
+			Duration:       tizRace.Duration,
+			AllDay:         tizRace.AllDay,
+			Times:          tizRace.Times,
+			UID:            tizRace.UID,
+			Revision:       tizRace.Revision,
 		}
 
 		// Parse times
 		if tizRace.AllDay {
 			event.StartTime = ""
 			event.EndTime = ""
-		} else if len(tizRace.Times) > 0 {
-			// Use first time slot as default
-			event.StartTime = tizRace.Times[0].Time
-			// Calculate end time using duration
-			event.EndTime = calculateEndTime(tizRace.Times[0].Time, tizRace.Duration)
+		} else if slot, _, ok := types.FirstSlot(tizRace.Times, tizRace.StartDate); ok {
+			// Use the first time slot of the race as default
+			event.StartTime = slot.Time
+			// Calculate end time using the duration of the slot
+			duration := slot.Duration
+			if duration == "" {
+				duration = tizRace.Duration
+			}
+			event.EndTime = calculateEndTime(slot, duration)
 		}
 
-		// Set info link (first non-stream link)
-		if len(tizRace.StreamLinks) > 0 {
-			// Use last link that's not a stream page
-			for _, link := range tizRace.StreamLinks {
-				if !strings.Contains(link, "stream") && !strings.Contains(link, "cyclingtiz") {
-					event.Link = link
-					break
-				}
-			}
-			if event.Link == "" && len(tizRace.StreamLinks) > 0 {
-				event.Link = tizRace.StreamLinks[0]
+		// Set info link (first info page, else first link)
+		for _, link := range tizRace.Links {
+			if link.Kind == types.LinkKindInfo {
+				event.Link = link.URL
+				break
 			}
 		}
+		if event.Link == "" && len(tizRace.StreamLinks) > 0 {
+			event.Link = tizRace.StreamLinks[0]
+		}
 
 		events = append(events, event)
 	}
@@ -156,9 +285,10 @@ func convertTizRacesToEvents(tizRaces []types.TizRace) []types.Event {
 	return events
 }
 
-// calculateEndTime calculates end time based on start time and duration
-func calculateEndTime(startTimeStr, duration string) string {
-	if startTimeStr == "" || duration == "" {
+// calculateEndTime calculates the end time of a slot, formatted as slot
+// times are (14:00:00 UTC), from its start and duration
+func calculateEndTime(slot types.TizTimeSlot, duration string) string {
+	if slot.Time == "" || duration == "" {
 		return ""
 	}
 
@@ -166,32 +296,23 @@ func calculateEndTime(startTimeStr, duration string) string {
 	durationMins := parseDurationMinutes(duration)
 	if durationMins == 0 {
 		// Default to +3 hours
-		return startTimeStr // Will be handled in ICS generation
-	}
-
-	// Parse start time - remove "UTC" suffix if present
-	cleanTime := strings.TrimSuffix(strings.TrimSpace(startTimeStr), "UTC")
-	cleanTime = strings.TrimSpace(cleanTime)
-	startParts := strings.Split(cleanTime, ":")
-	if len(startParts) != 2 {
-		return ""
+		return slot.Time // Will be handled in ICS generation
 	}
 
-	hour, err := strconv.Atoi(strings.TrimSpace(startParts[0]))
-	if err != nil {
-		return ""
-	}
-	minute, err := strconv.Atoi(strings.TrimSpace(startParts[1]))
-	if err != nil {
-		return ""
+	start := slot.Start
+	if start.IsZero() {
+		// Slots of races not resolved in a zone: 14:00:00 UTC or 14:00 UTC
+		clock := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(slot.Time), "UTC"))
+		var err error
+		if start, err = time.Parse("15:04:05", clock); err != nil {
+			if start, err = time.Parse("15:04", clock); err != nil {
+				return ""
+			}
+		}
 	}
 
-	// Calculate end time
-	totalMins := hour*60 + minute + durationMins
-	endHour := (totalMins / 60) % 24
-	endMin := totalMins % 60
-
-	return fmt.Sprintf("%02d:%02d UTC", endHour, endMin)
+	end := start.UTC().Add(time.Duration(durationMins) * time.Minute)
+	return end.Format("15:04:05") + " UTC"
 }
 
 // parseDurationMinutes converts duration string to minutes
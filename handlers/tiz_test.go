@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"cpe/calendar/config"
+	"cpe/calendar/request"
+	"cpe/calendar/types"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateTizICSHandlerReplay serves the calendar of a captured feed
+// through the file source, without network access
+func TestGenerateTizICSHandlerReplay(t *testing.T) {
+	cfg := config.Default()
+	cfg.Sources = []string{"file"}
+	cfg.ReplayPath = filepath.Join("..", "request", "testdata", "corpus", "2026-02-04.html")
+	cfg.DataDir = ""
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid replay configuration: %v", err)
+	}
+	if err := request.Configure(cfg); err != nil {
+		t.Fatalf("Configure returned error: %v", err)
+	}
+	Configure(cfg)
+
+	tests := []struct {
+		query   string
+		status  int
+		want    string
+		without string
+	}{
+		{"", http.StatusOK, "SUMMARY:Volta Comunitat Valenciana", ""},
+		{"?class=WE", http.StatusOK, "SUMMARY:UAE Tour Women", "Volta Comunitat Valenciana"},
+		{"?class=nope", http.StatusBadRequest, "", ""},
+		{"?discipline=cyclocross", http.StatusOK, "CATEGORIES:Cyclocross", "Volta Comunitat Valenciana"},
+		{"?discipline=Track&discipline=mtb", http.StatusOK, "CATEGORIES:Mountain Bike", "CATEGORIES:Road"},
+		{"?discipline=skating", http.StatusBadRequest, "", ""},
+		{"?uci=Pro", http.StatusOK, "(Men Elite) [2.Pro]", "Bessèges"},
+		{"?uci=.1&uci=wt", http.StatusOK, "(Women Elite) [2.WWT]", "Volta Comunitat Valenciana"},
+		{"?uci=HC", http.StatusBadRequest, "", ""},
+		{"?lang=nl", http.StatusOK, "Sluitingsprijs Oostmalle", "Muscat Classic"},
+		{"?lang=English", http.StatusOK, "Mediterranean Epic", "Muscat Classic"},
+		{"?lang=ar&lang=sl-SI", http.StatusOK, "Tour of Oman", "Sluitingsprijs"},
+		{"?lang=klingon", http.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/cycling-calendar.ics"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			GenerateTizICSHandler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != "text/calendar" {
+				t.Errorf("Expected text/calendar, got %q", ct)
+			}
+			body := rec.Body.String()
+			if !strings.HasPrefix(body, "BEGIN:VCALENDAR") || !strings.Contains(body, "BEGIN:VEVENT") {
+				t.Errorf("Expected a calendar with events, got:\n%s", body)
+			}
+			unfolded := strings.ReplaceAll(body, "\r\n ", "")
+			if strings.Count(body, "BEGIN:VEVENT") != strings.Count(unfolded, "@cycling.for-loop.fr\r\n") {
+				t.Error("Expected UIDs derived from the race identity")
+			}
+			if !strings.Contains(body, "SEQUENCE:0\r\nDTSTAMP:") {
+				t.Error("Expected every event to carry SEQUENCE and DTSTAMP")
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("Expected %q in the calendar", tt.want)
+			}
+			if tt.without != "" && strings.Contains(body, tt.without) {
+				t.Errorf("Did not expect %q in the calendar", tt.without)
+			}
+		})
+	}
+}
+
+func TestGenerateTizICSHandlerAcceptsEveryCategory(t *testing.T) {
+	cfg := config.Default()
+	cfg.Sources = []string{"file"}
+	cfg.ReplayPath = filepath.Join("..", "request", "testdata", "corpus", "2026-02-04.html")
+	cfg.DataDir = ""
+	if err := request.Configure(cfg); err != nil {
+		t.Fatalf("Configure returned error: %v", err)
+	}
+	Configure(cfg)
+
+	for code := range types.TizCategoryMap {
+		req := httptest.NewRequest(http.MethodGet, "/cycling-calendar.ics?class="+code, nil)
+		rec := httptest.NewRecorder()
+		GenerateTizICSHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected class %s to be allowed, got %d", code, rec.Code)
+		}
+	}
+}
+
+func TestConvertTizRacesToEventsTimes(t *testing.T) {
+	slot := func(clock string, start time.Time, duration, day string, dates ...string) types.TizTimeSlot {
+		return types.TizTimeSlot{Time: clock, Location: "UTC", Start: start, Duration: duration, Day: day, Dates: dates}
+	}
+	races := []types.TizRace{
+		{
+			Name: "Santos Tour Down Under", StartDate: "2026-01-20", EndDate: "2026-01-20", Duration: "2 hrs",
+			Times: []types.TizTimeSlot{slot("01:30:00 UTC", time.Date(2026, 1, 20, 1, 30, 0, 0, time.UTC), "90 mins", "")},
+		},
+		{
+			Name: "Race", StartDate: "2026-02-06", EndDate: "2026-02-08", Duration: "4 hrs",
+			Times: []types.TizTimeSlot{slot("10:00:00 UTC", time.Date(2026, 2, 7, 10, 0, 0, 0, time.UTC), "", "stages 2-3", "2026-02-07", "2026-02-08")},
+		},
+		{
+			Name: "Unresolved", StartDate: "2026-02-06", EndDate: "2026-02-06", Duration: "60 mins",
+			Times: []types.TizTimeSlot{{Time: "23:30 UTC"}},
+		},
+	}
+
+	events := convertTizRacesToEvents(races)
+	want := [][2]string{{"01:30:00 UTC", "03:00:00 UTC"}, {"10:00:00 UTC", "14:00:00 UTC"}, {"23:30 UTC", "00:30:00 UTC"}}
+	for i, event := range events {
+		if event.StartTime != want[i][0] || event.EndTime != want[i][1] {
+			t.Errorf("%s: expected %s-%s, got %s-%s", event.Title, want[i][0], want[i][1], event.StartTime, event.EndTime)
+		}
+	}
+}
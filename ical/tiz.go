@@ -1,6 +1,9 @@
 package ical
 
 import (
+	"cpe/calendar/config"
+	"cpe/calendar/countries"
+	"cpe/calendar/dates"
 	"cpe/calendar/logger"
 	"cpe/calendar/types"
 	"fmt"
@@ -10,15 +13,22 @@ import (
 	"time"
 )
 
-var monthMap = map[string]int{
-	"January": 1, "February": 2, "March": 3, "April": 4,
-	"May": 5, "June": 6, "July": 7,
-	"August": 8, "September": 9, "October": 10,
-	"November": 11, "December": 12,
+var (
+	// timezone is advertised to calendar clients as X-WR-TIMEZONE
+	timezone = "UTC"
+	// refreshInterval is the polling interval suggested to calendar clients
+	refreshInterval = time.Hour
+)
+
+// Configure applies the runtime configuration to the ical package
+func Configure(cfg config.Config) {
+	timezone = cfg.Timezone
+	refreshInterval = cfg.RefreshInterval
 }
 
-// GenerateTizICS generates an ICS string from a list of Tiz events
-func GenerateTizICS(events []types.Event, calendarName string) string {
+// GenerateTizICS generates an ICS string from a list of Tiz events.
+// A non-empty warning is emitted as an X-CYCLING-CALENDAR-WARNING property.
+func GenerateTizICS(events []types.Event, calendarName string, warning string) string {
 	// Start building ICS string with proper CRLF line endings
 	ics := "BEGIN:VCALENDAR\r\n"
 	ics += "VERSION:2.0\r\n"
@@ -27,14 +37,26 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 	ics += fmt.Sprintf("X-WR-CALNAME:%s\r\n", calendarName)
 	ics += fmt.Sprintf("Description:%s: %s\r\n", "Cycling Calendar", calendarName)
 	ics += fmt.Sprintf("X-WR-CALDESC:%s: %s\r\n", "Cycling Calendar", calendarName)
-	ics += "REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n"
-
-	// Get current year for parsing
-	currentYear := time.Now().Year()
+	if timezone != "" {
+		ics += fmt.Sprintf("X-WR-TIMEZONE:%s\r\n", timezone)
+	}
+	ics += fmt.Sprintf("REFRESH-INTERVAL;VALUE=DURATION:%s\r\n", formatICSDuration(refreshInterval))
+	ics += fmt.Sprintf("X-PUBLISHED-TTL:%s\r\n", formatICSDuration(refreshInterval))
+	if warning != "" {
+		ics += foldICSLine(fmt.Sprintf("X-CYCLING-CALENDAR-WARNING:%s", escapeICSText(warning)))
+	}
 
 	// Loop over each event and generate calendar content
 	// Loop over each event and generate calendar content
+	now := time.Now()
 	for _, event := range events {
+		if event.DatePrecision == types.DatePrecisionUnknown {
+			logger.Log.Warn().
+				Str("title", event.Title).
+				Msg("Skipping event without a resolvable date")
+			continue
+		}
+
 		summary := buildTizSummary(event)
 
 		// Build description with race info
@@ -55,11 +77,12 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 		description := descriptionBuilder.String()
 
 		var start, end time.Time
+		var ambiguity string
 		var err error
 
 		if event.AllDay {
 			// All-day event - date only
-			start, err = parseTizDateOnly(event.StartDate, currentYear)
+			start, err = dates.Parse(event.StartDate)
 			if err != nil {
 				logger.Log.Error().
 					Err(err).
@@ -67,7 +90,7 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 					Msg("Error parsing start date")
 				continue
 			}
-			end, err = parseTizDateOnly(event.EndDate, currentYear)
+			end, err = dates.Parse(event.EndDate)
 			if err != nil {
 				logger.Log.Warn().
 					Err(err).
@@ -76,33 +99,55 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 				end = start
 			}
 
+			// DTEND of an all-day event is exclusive: the day after the last race day
+			end = end.AddDate(0, 0, 1)
+
 			// Use date-only format
 			ics += "BEGIN:VEVENT\r\n"
-			ics += fmt.Sprintf("UID:%s%s\r\n", event.Title, event.Stage)
+			ics += foldICSLine(fmt.Sprintf("UID:%s", event.UID))
+			ics += formatRevision(event.Revision, now)
 			ics += fmt.Sprintf("DTSTART;VALUE=DATE:%s\r\n", start.Format("20060102"))
 			ics += fmt.Sprintf("DTEND;VALUE=DATE:%s\r\n", end.Format("20060102"))
 			ics += fmt.Sprintf("SUMMARY:%s\r\n", summary)
 			ics += foldICSLine(fmt.Sprintf("DESCRIPTION:%s", description))
-			if len(event.StreamLinks) > 0 {
-				ics += fmt.Sprintf("URL:%s\r\n", event.StreamLinks[0])
+			if event.Status != "" {
+				ics += fmt.Sprintf("STATUS:%s\r\n", event.Status)
+			}
+			ics += formatLocation(event)
+			if name, ok := types.DisciplineNames[event.Discipline]; ok {
+				ics += fmt.Sprintf("CATEGORIES:%s\r\n", name)
+			}
+			if url := eventURL(event); url != "" {
+				ics += foldICSLine(fmt.Sprintf("URL:%s", url))
 			}
 			ics += "END:VEVENT\r\n"
 
 		} else {
 			// Normal datetime event
-			if len(event.Times) > 0 {
-				// Parse start from first time slot
-				start, err = parseTizTime(event.Times[0].Time, event.StartDate)
+			if slot, date, ok := types.FirstSlot(event.Times, event.StartDate); ok {
+				// Parse start from the first time slot of the race, in the
+				// zone it was given in
+				if slot.Start.IsZero() {
+					start, err = parseTizTime(slot.Time, date)
+				} else {
+					start, err = slot.On(date)
+					start = start.UTC()
+				}
+				ambiguity = slot.Ambiguity
 				if err != nil {
 					logger.Log.Error().
 						Err(err).
-						Str("startTime", event.Times[0].Time).
+						Str("startTime", slot.Time).
 						Msg("Error parsing start time")
 					continue
 				}
 
-				// Calculate end time using duration
-				durationMins := parseDurationMinutes(event.Duration)
+				// Calculate end time using the duration of the slot
+				duration := slot.Duration
+				if duration == "" {
+					duration = event.Duration
+				}
+				durationMins := parseDurationMinutes(duration)
 				end = start.Add(time.Duration(durationMins) * time.Minute)
 			} else if event.StartTime != "" {
 				start, err = parseTizTime(event.StartTime, event.StartDate)
@@ -136,13 +181,24 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 
 			// Add event details to ICS string with proper CRLF line endings
 			ics += "BEGIN:VEVENT\r\n"
-			ics += fmt.Sprintf("UID:%s%s\r\n", event.Title, event.Stage)
+			ics += foldICSLine(fmt.Sprintf("UID:%s", event.UID))
+			ics += formatRevision(event.Revision, now)
 			ics += fmt.Sprintf("DTSTART:%s\r\n", start.Format("20060102T150405Z"))
 			ics += fmt.Sprintf("DTEND:%s\r\n", end.Format("20060102T150405Z"))
 			ics += fmt.Sprintf("SUMMARY:%s\r\n", summary)
 			ics += foldICSLine(fmt.Sprintf("DESCRIPTION:%s", description))
-			if len(event.StreamLinks) > 0 {
-				ics += fmt.Sprintf("URL:%s\r\n", event.StreamLinks[0])
+			if event.Status != "" {
+				ics += fmt.Sprintf("STATUS:%s\r\n", event.Status)
+			}
+			ics += formatLocation(event)
+			if name, ok := types.DisciplineNames[event.Discipline]; ok {
+				ics += fmt.Sprintf("CATEGORIES:%s\r\n", name)
+			}
+			if ambiguity != "" {
+				ics += foldICSLine(fmt.Sprintf("X-CYCLING-CALENDAR-AMBIGUOUS-TIME:%s", escapeICSText(ambiguity)))
+			}
+			if url := eventURL(event); url != "" {
+				ics += foldICSLine(fmt.Sprintf("URL:%s", url))
 			}
 			ics += "END:VEVENT\r\n"
 		}
@@ -159,10 +215,32 @@ func GenerateTizICS(events []types.Event, calendarName string) string {
 	return ics
 }
 
+// formatRevision formats the SEQUENCE, DTSTAMP, CREATED and LAST-MODIFIED
+// properties of an event. DTSTAMP is the time of the last revision, so it
+// only moves when the event changes.
+func formatRevision(revision types.Revision, now time.Time) string {
+	const layout = "20060102T150405Z"
+
+	props := fmt.Sprintf("SEQUENCE:%d\r\n", revision.Sequence)
+	if revision.LastModified.IsZero() {
+		return props + fmt.Sprintf("DTSTAMP:%s\r\n", now.UTC().Format(layout))
+	}
+	props += fmt.Sprintf("DTSTAMP:%s\r\n", revision.LastModified.UTC().Format(layout))
+	props += fmt.Sprintf("CREATED:%s\r\n", revision.Created.UTC().Format(layout))
+	props += fmt.Sprintf("LAST-MODIFIED:%s\r\n", revision.LastModified.UTC().Format(layout))
+	return props
+}
+
 // buildTizSummary builds the event summary from race data
 func buildTizSummary(event types.Event) string {
 	var summary strings.Builder
 
+	// Cancellation, for the clients that ignore STATUS
+	if event.Status == types.StatusCancelled && event.StatusNote != "" {
+		summary.WriteString(event.StatusNote)
+		summary.WriteString(": ")
+	}
+
 	// Title
 	summary.WriteString(event.Title)
 
@@ -187,6 +265,13 @@ func buildTizSummary(event types.Event) string {
 		summary.WriteString(")")
 	}
 
+	// UCI classification
+	if event.Classification != "" {
+		summary.WriteString(" [")
+		summary.WriteString(event.Classification)
+		summary.WriteString("]")
+	}
+
 	return summary.String()
 }
 
@@ -195,10 +280,17 @@ func buildTizEventDescription(event types.Event) []string {
 	var lines []string
 
 	// Add country
-	if event.Country != "" {
+	if event.CountryName != "" {
+		lines = append(lines, fmt.Sprintf(" Country: %s (%s)", event.CountryName, event.Country))
+	} else if event.Country != "" {
 		lines = append(lines, fmt.Sprintf(" Country: %s", event.Country))
 	}
 
+	// Add the status marked by the feed
+	if event.StatusNote != "" {
+		lines = append(lines, fmt.Sprintf(" Status: %s", event.StatusNote))
+	}
+
 	// Add categories
 	if len(event.Categories) > 0 {
 		catNames := make([]string, len(event.Categories))
@@ -220,6 +312,13 @@ func buildTizEventDescription(event types.Event) []string {
 		lines = append(lines, fmt.Sprintf(" Commentary: %s", event.StreamLang))
 	}
 
+	// Add approximate dates
+	if event.DatePrecision == types.DatePrecisionMonth {
+		if start, err := dates.Parse(event.StartDate); err == nil {
+			lines = append(lines, fmt.Sprintf(" Date: %s (exact date TBC)", start.Format("January 2006")))
+		}
+	}
+
 	// Add duration
 	if event.Duration != "" {
 		lines = append(lines, fmt.Sprintf(" Duration: %s", event.Duration))
@@ -229,7 +328,7 @@ func buildTizEventDescription(event types.Event) []string {
 	if len(event.Times) > 0 {
 		lines = append(lines, " Time slots:")
 		for _, timeSlot := range event.Times {
-			lines = append(lines, fmt.Sprintf("  %s: %s (%s)", timeSlot.Category, timeSlot.Time, timeSlot.Duration))
+			lines = append(lines, "  "+formatTimeSlot(timeSlot))
 		}
 	}
 
@@ -238,45 +337,104 @@ func buildTizEventDescription(event types.Event) []string {
 		lines = append(lines, fmt.Sprintf(" Note: %s", event.Notes))
 	}
 
-	// Add all stream links
-	if len(event.StreamLinks) > 0 {
-		lines = append(lines, " Stream links:")
-		for _, link := range event.StreamLinks {
-			lines = append(lines, fmt.Sprintf("  - %s", link))
+	// Add the broadcasts, then the info pages, as labelled by the feed
+	var watch, info []string
+	for _, link := range event.Links {
+		if link.Watchable() {
+			watch = append(watch, "  - "+formatLink(link))
+		} else {
+			info = append(info, "  - "+formatLink(link))
 		}
 	}
-
-	// Add info link
-	if event.Link != "" {
+	if len(watch) > 0 {
+		lines = append(lines, " Watch:")
+		lines = append(lines, watch...)
+	}
+	if len(info) > 0 {
+		lines = append(lines, " More info:")
+		lines = append(lines, info...)
+	} else if event.Link != "" {
 		lines = append(lines, fmt.Sprintf(" More info: %s", event.Link))
 	}
 
 	return lines
 }
 
-// parseTizDateOnly parses a date-only string (e.g., "2026-02-04")
-func parseTizDateOnly(dateStr string, year int) (time.Time, error) {
-	dateParts := strings.Split(dateStr, "-")
-	if len(dateParts) != 3 {
-		return time.Time{}, fmt.Errorf("invalid date format: %s", dateStr)
+// formatLocation formats the LOCATION of an event, its country, and the GEO
+// of the centre of that country
+func formatLocation(event types.Event) string {
+	country, ok := countries.Lookup(event.Country)
+	if !ok {
+		return ""
+	}
+	return foldICSLine(fmt.Sprintf("LOCATION:%s", escapeICSText(country.Name))) +
+		fmt.Sprintf("GEO:%s\r\n", country.Geo())
+}
+
+// formatLink formats a link as "Link (Spanish): https://..."
+func formatLink(link types.TizLink) string {
+	label := link.Label
+	if link.Language != "" {
+		label = strings.TrimSpace(fmt.Sprintf("%s (%s)", label, link.Language))
+	}
+	if label == "" {
+		return link.URL
+	}
+	return fmt.Sprintf("%s: %s", label, link.URL)
+}
+
+// eventURL is the URL of an event: its first broadcast, else its info page
+func eventURL(event types.Event) string {
+	for _, link := range event.Links {
+		if link.Watchable() {
+			return link.URL
+		}
+	}
+	if event.Link != "" {
+		return event.Link
+	}
+	if len(event.StreamLinks) > 0 {
+		return event.StreamLinks[0]
 	}
+	return ""
+}
 
-	month, _ := strconv.Atoi(dateParts[1])
-	day, _ := strconv.Atoi(dateParts[2])
+// formatTimeSlot formats a time slot as "Men U23, stages 2-4: 10:00:00 UTC [12:00 CEST] (2 hrs)"
+func formatTimeSlot(slot types.TizTimeSlot) string {
+	var label []string
+	if slot.Label != "" {
+		label = append(label, slot.Label)
+	} else if slot.Category != "" {
+		label = append(label, slot.Category)
+	}
+	if slot.Day != "" {
+		label = append(label, slot.Day)
+	}
 
-	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
+	line := slot.Time
+	if slot.Zone != "" && slot.Zone != "UTC" && !slot.Start.IsZero() {
+		// Keep the time as announced next to its UTC equivalent
+		line += fmt.Sprintf(" [%s %s]", slot.Start.Format("15:04"), slot.Zone)
+	}
+	if len(label) > 0 {
+		line = strings.Join(label, ", ") + ": " + line
+	}
+	if slot.Duration != "" {
+		line += fmt.Sprintf(" (%s)", slot.Duration)
+	}
+	if slot.Ambiguity != "" {
+		line += fmt.Sprintf(" - uncertain: %s", slot.Ambiguity)
+	}
+	return line
 }
 
 // parseTizTime parses a time string (e.g., "14:00 UTC") combined with a date string
 func parseTizTime(timeStr, dateStr string) (time.Time, error) {
 	// Parse date part
-	dateParts := strings.Split(dateStr, "-")
-	if len(dateParts) != 3 {
-		return time.Time{}, fmt.Errorf("invalid date format: %s", dateStr)
+	date, err := dates.Parse(dateStr)
+	if err != nil {
+		return time.Time{}, err
 	}
-	year, _ := strconv.Atoi(dateParts[0])
-	month, _ := strconv.Atoi(dateParts[1])
-	day, _ := strconv.Atoi(dateParts[2])
 
 	// Parse time part (HH:MM UTC or HH:MM:SS UTC)
 	// Remove " UTC" suffix and trim
@@ -295,7 +453,7 @@ func parseTizTime(timeStr, dateStr string) (time.Time, error) {
 		second, _ = strconv.Atoi(strings.TrimSpace(timeParts[2]))
 	}
 
-	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC), nil
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, 0, time.UTC), nil
 }
 
 // parseDurationMinutes converts duration string to minutes
@@ -325,6 +483,26 @@ func parseDurationMinutes(duration string) int {
 	}
 }
 
+// formatICSDuration formats a duration as an RFC 5545 duration (e.g. PT1H30M)
+func formatICSDuration(d time.Duration) string {
+	if d <= 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := int(d / time.Hour); hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes := int(d % time.Hour / time.Minute); minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+	}
+	if seconds := int(d % time.Minute / time.Second); seconds > 0 {
+		fmt.Fprintf(&b, "%dS", seconds)
+	}
+	return b.String()
+}
+
 // escapeICSText properly escapes text for ICS format
 func escapeICSText(text string) string {
 	// Escape backslashes, commas, and semicolons
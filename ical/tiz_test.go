@@ -0,0 +1,146 @@
+package ical
+
+import (
+	"cpe/calendar/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateTizICSAllDayEnd(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		precision string
+		dtend     string
+	}{
+		{"one day", "2026-02-06", "2026-02-06", types.DatePrecisionDay, "20260207"},
+		{"three days", "2026-02-06", "2026-02-08", types.DatePrecisionDay, "20260209"},
+		{"seven days", "2026-02-06", "2026-02-12", types.DatePrecisionDay, "20260213"},
+		{"across months", "2026-02-28", "2026-03-01", types.DatePrecisionDay, "20260302"},
+		{"month only", "2026-03-01", "2026-03-31", types.DatePrecisionMonth, "20260401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := types.Event{
+				UID:           "race@example.org",
+				Title:         "Race",
+				StartDate:     tt.start,
+				EndDate:       tt.end,
+				DatePrecision: tt.precision,
+				AllDay:        true,
+			}
+			ics := GenerateTizICS([]types.Event{event}, "Test", "")
+
+			start := strings.ReplaceAll(tt.start, "-", "")
+			if !strings.Contains(ics, "DTSTART;VALUE=DATE:"+start+"\r\n") {
+				t.Errorf("Expected DTSTART %s in:\n%s", start, ics)
+			}
+			if !strings.Contains(ics, "DTEND;VALUE=DATE:"+tt.dtend+"\r\n") {
+				t.Errorf("Expected exclusive DTEND %s in:\n%s", tt.dtend, ics)
+			}
+		})
+	}
+}
+
+func TestGenerateTizICSLinks(t *testing.T) {
+	event := types.Event{
+		UID:       "race@example.org",
+		Title:     "Muscat Classic",
+		StartDate: "2026-02-06",
+		EndDate:   "2026-02-06",
+		AllDay:    true,
+		Link:      "https://www.procyclingstats.com/race/muscat-classic",
+		Links: []types.TizLink{
+			{URL: "https://cyclingtiz.live/", Label: "Stream Page", Kind: types.LinkKindStream},
+			{URL: "https://ayn.om/live/159", Label: "Link", Kind: types.LinkKindDirect, Language: "Arabic"},
+			{URL: "https://www.procyclingstats.com/race/muscat-classic", Label: "Info", Kind: types.LinkKindInfo},
+		},
+	}
+	ics := strings.ReplaceAll(GenerateTizICS([]types.Event{event}, "Test", ""), "\r\n ", "")
+
+	for _, want := range []string{
+		"URL:https://cyclingtiz.live/\r\n",
+		"DESCRIPTION: Watch:\\n  - Stream Page: https://cyclingtiz.live/\\n  - Link (Arabic): https://ayn.om/live/159\\n",
+		"\\n More info:\\n  - Info: https://www.procyclingstats.com/race/muscat-classic",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in:\n%s", want, ics)
+		}
+	}
+}
+
+func TestGenerateTizICSLocation(t *testing.T) {
+	event := types.Event{
+		UID:         "race@example.org",
+		Title:       "Muscat Classic",
+		Country:     "OM",
+		CountryName: "Oman",
+		StartDate:   "2026-02-06",
+		EndDate:     "2026-02-06",
+		AllDay:      true,
+	}
+	ics := GenerateTizICS([]types.Event{event}, "Test", "")
+	for _, want := range []string{"LOCATION:Oman\r\n", "GEO:21.51;55.92\r\n", " Country: Oman (OM)"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in:\n%s", want, ics)
+		}
+	}
+
+	event.Country, event.CountryName = "", ""
+	ics = GenerateTizICS([]types.Event{event}, "Test", "")
+	if strings.Contains(ics, "LOCATION:") || strings.Contains(ics, "GEO:") {
+		t.Errorf("Expected no location without a country in:\n%s", ics)
+	}
+}
+
+func TestGenerateTizICSStatus(t *testing.T) {
+	event := types.Event{
+		UID:        "race@example.org",
+		Title:      "Strade Bianche",
+		Status:     types.StatusCancelled,
+		StatusNote: "Postponed",
+		StartDate:  "2026-03-07",
+		EndDate:    "2026-03-07",
+		AllDay:     true,
+	}
+	ics := GenerateTizICS([]types.Event{event}, "Test", "")
+	for _, want := range []string{"STATUS:CANCELLED\r\n", "SUMMARY:Postponed: Strade Bianche\r\n", " Status: Postponed"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in:\n%s", want, ics)
+		}
+	}
+
+	event.Status, event.StatusNote = types.StatusTentative, ""
+	ics = GenerateTizICS([]types.Event{event}, "Test", "")
+	if !strings.Contains(ics, "STATUS:TENTATIVE\r\n") || !strings.Contains(ics, "SUMMARY:Strade Bianche\r\n") {
+		t.Errorf("Expected a tentative event in:\n%s", ics)
+	}
+}
+
+func TestGenerateTizICSDayRestrictedSlots(t *testing.T) {
+	event := types.Event{
+		UID:           "race@example.org",
+		Title:         "Race",
+		StartDate:     "2026-02-06",
+		EndDate:       "2026-02-08",
+		DatePrecision: types.DatePrecisionDay,
+		Duration:      "4 hrs",
+		Times: []types.TizTimeSlot{{
+			Time:     "10:00:00 UTC",
+			Location: "UTC",
+			Start:    time.Date(2026, 2, 7, 10, 0, 0, 0, time.UTC),
+			Duration: "90 mins",
+			Day:      "stages 2-3",
+			Dates:    []string{"2026-02-07", "2026-02-08"},
+		}},
+	}
+	ics := GenerateTizICS([]types.Event{event}, "Test", "")
+	for _, want := range []string{"DTSTART:20260207T100000Z\r\n", "DTEND:20260207T113000Z\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in:\n%s", want, ics)
+		}
+	}
+}
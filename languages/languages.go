@@ -0,0 +1,116 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// byName maps the lower-cased language names written by the feed, in
+	// English or in the language itself, to their BCP 47 tag
+	byName = map[string]string{
+		"afrikaans": "af", "albanian": "sq", "amharic": "am", "arabic": "ar", "armenian": "hy",
+		"azerbaijani": "az", "basque": "eu", "euskara": "eu", "belarusian": "be", "bosnian": "bs",
+		"brazilian": "pt-BR", "brazilian portuguese": "pt-BR", "bulgarian": "bg",
+		"cantonese": "yue", "castilian": "es", "catalan": "ca", "català": "ca", "chinese": "zh",
+		"croatian": "hr", "czech": "cs", "danish": "da", "dansk": "da", "dutch": "nl", "nederlands": "nl",
+		"english": "en", "estonian": "et", "farsi": "fa", "finnish": "fi", "suomi": "fi",
+		"flemish": "nl-BE", "vlaams": "nl-BE", "french": "fr", "français": "fr", "francais": "fr",
+		"galician": "gl", "georgian": "ka", "german": "de", "deutsch": "de", "greek": "el",
+		"hebrew": "he", "hindi": "hi", "hungarian": "hu", "magyar": "hu", "icelandic": "is",
+		"indonesian": "id", "irish": "ga", "italian": "it", "italiano": "it", "japanese": "ja",
+		"kazakh": "kk", "kinyarwanda": "rw", "korean": "ko", "latin american spanish": "es-419",
+		"latvian": "lv", "lithuanian": "lt", "luxembourgish": "lb", "macedonian": "mk",
+		"malay": "ms", "maltese": "mt", "mandarin": "zh", "norwegian": "no", "norsk": "no",
+		"persian": "fa", "polish": "pl", "polski": "pl", "portuguese": "pt", "português": "pt",
+		"portugues": "pt", "romanian": "ro", "russian": "ru", "serbian": "sr", "slovak": "sk",
+		"slovenian": "sl", "slovene": "sl", "slovenščina": "sl", "spanish": "es", "español": "es",
+		"espanol": "es", "swahili": "sw", "swedish": "sv", "svenska": "sv", "swiss german": "gsw",
+		"thai": "th", "tigrinya": "ti", "turkish": "tr", "ukrainian": "uk", "urdu": "ur",
+		"uzbek": "uz", "vietnamese": "vi", "welsh": "cy",
+	}
+
+	// primaryTags are the primary language subtags of byName
+	primaryTags = func() map[string]bool {
+		tags := map[string]bool{}
+		for _, tag := range byName {
+			primary, _, _ := strings.Cut(tag, "-")
+			tags[primary] = true
+		}
+		return tags
+	}()
+
+	// listSeparator splits "English or Spanish", "Dutch/French", "Spanish, English & Basque"
+	listSeparator = regexp.MustCompile(`(?i)\s*(?:,|/|&|\+|;|\bor\b|\band\b)\s*`)
+)
+
+// Lookup returns the BCP 47 tag of a language name (Spanish, Español) or
+// tag (es, ES, es-419), in any case, restricted to the languages known to
+// the calendar
+func Lookup(name string) (string, bool) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if tag, ok := byName[name]; ok {
+		return tag, true
+	}
+	return canonicalTag(name)
+}
+
+// Parse returns the BCP 47 tags of the languages of a list as written by
+// the feed ("English or Spanish"), without duplicates. Unknown names are
+// skipped.
+func Parse(text string) []string {
+	var tags []string
+	for _, name := range listSeparator.Split(strings.TrimSpace(text), -1) {
+		tag, ok := Lookup(name)
+		if !ok {
+			continue
+		}
+		duplicate := false
+		for _, seen := range tags {
+			duplicate = duplicate || seen == tag
+		}
+		if !duplicate {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Matches tells whether any of tags is within the range of want, so en
+// matches en and en-GB (RFC 4647 basic filtering)
+func Matches(tags []string, want string) bool {
+	for _, tag := range tags {
+		if strings.EqualFold(tag, want) || (len(tag) > len(want) && tag[len(want)] == '-' && strings.EqualFold(tag[:len(want)], want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalTag returns the canonical case of a language tag made of a known
+// primary subtag and optional script and region subtags (zh-Hant, es-419)
+func canonicalTag(tag string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	if !primaryTags[parts[0]] || len(parts) > 3 {
+		return "", false
+	}
+
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		switch {
+		case len(part) == 4 && i == 1 && isLetters(part):
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		case len(part) == 2 && isLetters(part):
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 3 && strings.Trim(part, "0123456789") == "":
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// isLetters tells whether s is made of ASCII letters only
+func isLetters(s string) bool {
+	return strings.Trim(s, "abcdefghijklmnopqrstuvwxyz") == ""
+}
@@ -0,0 +1,63 @@
+package languages
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Spanish", []string{"es"}},
+		{"English or Spanish", []string{"en", "es"}},
+		{"Flemish", []string{"nl-BE"}},
+		{" Dutch / French ", []string{"nl", "fr"}},
+		{"Spanish, English & Basque", []string{"es", "en", "eu"}},
+		{"Latin American Spanish", []string{"es-419"}},
+		{"Español and Castilian", []string{"es"}},
+		{"EN", []string{"en"}},
+		{"language", nil},
+		{"Klingon or Slovenian", []string{"sl"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, expected %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"en", "en", true},
+		{"EN-gb", "en-GB", true},
+		{"es_419", "es-419", true},
+		{"zh-hant-tw", "zh-Hant-TW", true},
+		{"German", "de", true},
+		{"xx", "", false},
+		{"en-toolong", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := Lookup(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %q, %v, expected %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tags := []string{"nl-BE", "fr"}
+	for want, expected := range map[string]bool{"nl": true, "nl-BE": true, "nl-NL": false, "fr": true, "f": false, "en": false} {
+		if got := Matches(tags, want); got != expected {
+			t.Errorf("Matches(%v, %q) = %v, expected %v", tags, want, got, expected)
+		}
+	}
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
+	"cpe/calendar/config"
 	"cpe/calendar/handlers"
+	"cpe/calendar/ical"
 	"cpe/calendar/logger"
 	"cpe/calendar/metrics"
+	"cpe/calendar/request"
 
+	"fmt"
 	"html/template"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
+	_ "time/tzdata" // Race times are resolved in zones the runtime image may lack
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -27,10 +35,6 @@ func init() {
 		// Log error and exit if environment variables can't be loaded
 		logger.Log.Warn().Err(err).Msg("Error loading .env file")
 	}
-	// Warn if TIMEZONE is not set
-	if tz := os.Getenv("TIMEZONE"); tz == "" {
-		logger.Log.Warn().Msg("TIMEZONE environment variable is not set")
-	}
 
 	// Parse templates
 	tpl = template.Must(template.ParseFiles(filepath.Join("static", "index.html")))
@@ -38,17 +42,78 @@ func init() {
 	prometheus.Register(metrics.TotalRequests)
 	prometheus.Register(metrics.ResponseStatus)
 	prometheus.Register(metrics.HttpDuration)
+	prometheus.Register(metrics.SnapshotTimestamp)
+	prometheus.Register(metrics.UpstreamFailures)
+	prometheus.Register(metrics.UnresolvedRaces)
+	prometheus.Register(metrics.ParseTimestamp)
+	prometheus.Register(metrics.ParseEntriesSeen)
+	prometheus.Register(metrics.ParseRacesParsed)
+	prometheus.Register(metrics.ParseEntriesSkipped)
+	prometheus.Register(metrics.ParseFieldFailures)
 }
 
 func main() {
+	// Subcommands (history, diff) run instead of the server
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Load and validate the runtime configuration
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Invalid configuration")
+	}
+	cfg.Log()
+
+	// Inject the configuration into the packages that use it
+	if err := request.Configure(cfg); err != nil {
+		logger.Log.Fatal().Err(err).Strs("available", request.RegisteredSources()).Msg("Invalid race source configuration")
+	}
+	handlers.Configure(cfg)
+	ical.Configure(cfg)
+
+	// Restore the last Tiz snapshot so a restart does not require the upstream
+	if err := request.LoadSnapshot(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring Tiz snapshot on disk")
+	}
+	if err := request.LoadUIDs(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring UID table on disk, renamed races get new UIDs")
+	}
+	if err := request.LoadRevisions(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring race revisions on disk, sequences restart at 0")
+	}
+	if err := request.LoadCancellations(); err != nil {
+		logger.Log.Warn().Err(err).Msg("Ignoring cancelled races on disk, dropped races are no longer served cancelled")
+	}
+
 	// Set up graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Shutdown goroutine
+	// Keep the races of every source fresh in the background
+	ctx, cancel := context.WithCancel(context.Background())
+	var watchers sync.WaitGroup
+	watchers.Add(2)
+	go func() {
+		defer watchers.Done()
+		request.StartSources(ctx)
+	}()
+	go func() {
+		defer watchers.Done()
+		request.WatchOverrides(ctx)
+	}()
+
+	// Shutdown goroutine, waits for the background updates to stop so no
+	// state file is left half written
 	go func() {
 		<-sigChan
 		logger.Log.Info().Msg("Shutting down gracefully...")
+		cancel()
+		watchers.Wait()
 		os.Exit(0)
 	}()
 
@@ -75,9 +140,16 @@ func main() {
 	// check app health
 	r.HandleFunc("/health", handlers.Health).Methods("GET")
 
+	// Archived snapshots and what changed between them
+	r.HandleFunc("/api/history", handlers.HistoryHandler).Methods("GET")
+	r.HandleFunc("/api/history/diff", handlers.HistoryDiffHandler).Methods("GET")
+
+	// Parser diagnostics, only served with the configured debug token
+	r.HandleFunc("/debug/parse", handlers.DebugParseHandler).Methods("GET")
+
 	// Start HTTP server and log any errors that occur
-	logger.Log.Info().Msg("Starting server on :8080")
-	err := http.ListenAndServe(":8080", r)
+	logger.Log.Info().Str("addr", cfg.Addr).Msg("Starting server")
+	err = http.ListenAndServe(cfg.Addr, r)
 	if err != nil {
 		// Log any errors that occur while starting server
 		logger.Log.Fatal().Err(err).Msg("Error starting server")
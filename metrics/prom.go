@@ -44,6 +44,46 @@ var HttpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Help: "Duration of HTTP requests.",
 }, []string{"path"})
 
+var SnapshotTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_snapshot_timestamp_seconds",
+	Help: "Unix time of the last successful Tiz schedule fetch.",
+})
+
+var UpstreamFailures = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_upstream_consecutive_failures",
+	Help: "Consecutive failed Tiz schedule fetches.",
+})
+
+var UnresolvedRaces = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_races_unresolved_dates",
+	Help: "Races of the last Tiz schedule without a resolvable date.",
+})
+
+var ParseTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_parse_timestamp_seconds",
+	Help: "Unix time of the last parsed Tiz schedule.",
+})
+
+var ParseEntriesSeen = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_parse_entries_seen",
+	Help: "Entries found in the last parsed Tiz schedule.",
+})
+
+var ParseRacesParsed = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "tiz_parse_races_parsed",
+	Help: "Races parsed from the last Tiz schedule.",
+})
+
+var ParseEntriesSkipped = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "tiz_parse_entries_skipped",
+	Help: "Entries of the last Tiz schedule that did not produce a race.",
+}, []string{"reason"})
+
+var ParseFieldFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "tiz_parse_field_failures",
+	Help: "Fields of races of the last Tiz schedule that could not be parsed.",
+}, []string{"field"})
+
 func PrometheusMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		route := mux.CurrentRoute(r)
@@ -0,0 +1,237 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	archiveDirname = "archive"
+	archiveExt     = ".json.gz"
+	// archiveIDLayout names archived snapshots after their fetch time, so
+	// they sort chronologically
+	archiveIDLayout = "20060102T150405Z"
+)
+
+// ErrNoArchive is returned when there are not enough archived snapshots
+var ErrNoArchive = errors.New("no archived snapshot")
+
+// ArchiveEntry is a snapshot stored in the archive
+type ArchiveEntry struct {
+	ID        string    `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Size      int64     `json:"size"` // Compressed size in bytes
+}
+
+// ArchiveDiff is the difference between two archived snapshots
+type ArchiveDiff struct {
+	From ArchiveEntry `json:"from"`
+	To   ArchiveEntry `json:"to"`
+	RaceDiff
+}
+
+// archiveDir returns the directory of the archive, empty when persistence is disabled
+func archiveDir() string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, archiveDirname)
+}
+
+// archiveSnapshot stores a compressed copy of a fetched snapshot
+func archiveSnapshot(snapshot Snapshot) (ArchiveEntry, error) {
+	dir := archiveDir()
+	if dir == "" {
+		return ArchiveEntry{}, nil
+	}
+
+	snapshot.Version = snapshotVersion
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to encode archived snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return ArchiveEntry{}, fmt.Errorf("failed to compress archived snapshot: %w", err)
+	}
+
+	id := snapshot.FetchedAt.UTC().Format(archiveIDLayout)
+	if err := writeFileAtomic(filepath.Join(dir, id+archiveExt), buf.Bytes()); err != nil {
+		return ArchiveEntry{}, err
+	}
+
+	return ArchiveEntry{ID: id, FetchedAt: snapshot.FetchedAt.UTC().Truncate(time.Second), Size: int64(buf.Len())}, nil
+}
+
+// ListArchive returns the archived snapshots, oldest first
+func ListArchive() ([]ArchiveEntry, error) {
+	dir := archiveDir()
+	if dir == "" {
+		return nil, nil
+	}
+
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive: %w", err)
+	}
+
+	var entries []ArchiveEntry
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), archiveExt)
+		if !ok || file.IsDir() {
+			continue
+		}
+		fetchedAt, err := time.Parse(archiveIDLayout, id)
+		if err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, ArchiveEntry{ID: id, FetchedAt: fetchedAt, Size: info.Size()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries, nil
+}
+
+// LoadArchived reads an archived snapshot
+func LoadArchived(id string) (Snapshot, error) {
+	if _, err := time.Parse(archiveIDLayout, id); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot id %q", id)
+	}
+	dir := archiveDir()
+	if dir == "" {
+		return Snapshot{}, ErrNoArchive
+	}
+
+	file, err := os.Open(filepath.Join(dir, id+archiveExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrNoArchive, id)
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to open archived snapshot: %w", err)
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decompress archived snapshot %s: %w", id, err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decompress archived snapshot %s: %w", id, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode archived snapshot %s: %w", id, err)
+	}
+	if err := upgradeSnapshot(&snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("archived snapshot %s: %w", id, err)
+	}
+
+	return snapshot, nil
+}
+
+// DiffArchived compares two archived snapshots. An empty to is the latest
+// snapshot, an empty from the one archived just before to.
+func DiffArchived(from, to string) (ArchiveDiff, error) {
+	entries, err := ListArchive()
+	if err != nil {
+		return ArchiveDiff{}, err
+	}
+
+	if len(entries) == 0 {
+		return ArchiveDiff{}, ErrNoArchive
+	}
+
+	toIndex := len(entries) - 1
+	if to != "" {
+		toIndex = archiveIndex(entries, to)
+	}
+	if toIndex < 0 {
+		return ArchiveDiff{}, fmt.Errorf("%w: %s", ErrNoArchive, to)
+	}
+	fromIndex := toIndex - 1
+	if from != "" {
+		fromIndex = archiveIndex(entries, from)
+	}
+	if fromIndex < 0 {
+		if from == "" {
+			return ArchiveDiff{}, fmt.Errorf("%w before %s", ErrNoArchive, entries[toIndex].ID)
+		}
+		return ArchiveDiff{}, fmt.Errorf("%w: %s", ErrNoArchive, from)
+	}
+
+	older, err := LoadArchived(entries[fromIndex].ID)
+	if err != nil {
+		return ArchiveDiff{}, err
+	}
+	newer, err := LoadArchived(entries[toIndex].ID)
+	if err != nil {
+		return ArchiveDiff{}, err
+	}
+
+	return ArchiveDiff{
+		From:     entries[fromIndex],
+		To:       entries[toIndex],
+		RaceDiff: DiffRaces(older.Races, newer.Races),
+	}, nil
+}
+
+// archiveIndex returns the position of id in entries, or -1
+func archiveIndex(entries []ArchiveEntry, id string) int {
+	for i, entry := range entries {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordSnapshot archives a newly fetched snapshot and logs how its races
+// differ from the previous one
+func recordSnapshot(previous []types.TizRace, snapshot Snapshot) {
+	entry, err := archiveSnapshot(snapshot)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to archive Tiz snapshot")
+	} else if entry.ID != "" {
+		logger.Log.Debug().Str("id", entry.ID).Int64("size", entry.Size).Msg("Archived Tiz snapshot")
+	}
+
+	if len(previous) == 0 {
+		return
+	}
+	diff := DiffRaces(previous, snapshot.Races)
+	if diff.Empty() {
+		return
+	}
+	logger.Log.Info().
+		Int("added", len(diff.Added)).
+		Int("removed", len(diff.Removed)).
+		Int("modified", len(diff.Modified)).
+		Msg("Tiz schedule changed")
+	for _, change := range diff.Modified {
+		fields := make([]string, len(change.Changes))
+		for i, field := range change.Changes {
+			fields[i] = field.Field
+		}
+		logger.Log.Info().Str("race", change.Name).Str("stage", change.Stage).Strs("fields", fields).Msg("Race modified")
+	}
+}
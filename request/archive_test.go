@@ -0,0 +1,60 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestArchiveDiff(t *testing.T) {
+	resetRaceCache(t, "")
+
+	if _, err := DiffArchived("", ""); !errors.Is(err, ErrNoArchive) {
+		t.Errorf("Expected ErrNoArchive on an empty archive, got %v", err)
+	}
+
+	first := time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+	snapshots := []Snapshot{
+		{FetchedAt: first, Races: []types.TizRace{{Name: "Tour of Oman", StartDate: "2026-02-07"}}},
+		{FetchedAt: first.Add(24 * time.Hour), Races: []types.TizRace{{Name: "Tour of Oman", StartDate: "2026-02-08"}}},
+		{FetchedAt: first.Add(48 * time.Hour), Races: []types.TizRace{{Name: "Tour of Oman", StartDate: "2026-02-08"}, {Name: "Muscat Classic"}}},
+	}
+	for _, snapshot := range snapshots {
+		if _, err := archiveSnapshot(snapshot); err != nil {
+			t.Fatalf("archiveSnapshot returned error: %v", err)
+		}
+	}
+
+	entries, err := ListArchive()
+	if err != nil {
+		t.Fatalf("ListArchive returned error: %v", err)
+	}
+	if len(entries) != 3 || entries[0].ID != "20260204T060000Z" || !entries[2].FetchedAt.Equal(first.Add(48*time.Hour)) {
+		t.Fatalf("Unexpected archive entries: %+v", entries)
+	}
+
+	// Latest against the previous one
+	diff, err := DiffArchived("", "")
+	if err != nil {
+		t.Fatalf("DiffArchived returned error: %v", err)
+	}
+	if diff.From.ID != "20260205T060000Z" || len(diff.Added) != 1 || len(diff.Modified) != 0 {
+		t.Errorf("Unexpected diff of the latest snapshot: %+v", diff)
+	}
+
+	diff, err = DiffArchived("20260204T060000Z", "20260205T060000Z")
+	if err != nil {
+		t.Fatalf("DiffArchived returned error: %v", err)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].Changes[0].Field != "start_date" {
+		t.Errorf("Expected the moved start date, got %+v", diff)
+	}
+
+	if _, err := DiffArchived("", "20260204T060000Z"); !errors.Is(err, ErrNoArchive) {
+		t.Errorf("Expected ErrNoArchive before the first snapshot, got %v", err)
+	}
+	if _, err := DiffArchived("../snapshot", ""); !errors.Is(err, ErrNoArchive) {
+		t.Errorf("Expected ErrNoArchive for an unknown id, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/metrics"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// backoffBase is the delay after the first failed fetch, doubled on each failure
+	backoffBase = 30 * time.Second
+	// backoffMax caps the exponential backoff delay
+	backoffMax = 1 * time.Hour
+	// breakerThreshold is the number of consecutive failures that opens the circuit
+	breakerThreshold = 5
+	// breakerCooldown is how long the circuit stays open before a trial fetch
+	breakerCooldown = 2 * time.Hour
+
+	// jitter spreads a delay over [d/2, d] so retries do not synchronise
+	jitter = func(d time.Duration) time.Duration {
+		half := d / 2
+		return half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+
+	errBackingOff  = errors.New("upstream fetch is backing off")
+	errCircuitOpen = errors.New("upstream circuit breaker is open")
+
+	breaker struct {
+		sync.Mutex
+		failures    int
+		nextAttempt time.Time
+		openUntil   time.Time
+	}
+)
+
+// allowFetch returns an error when the upstream must not be contacted yet
+func allowFetch(now time.Time) error {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	if now.Before(breaker.openUntil) {
+		return fmt.Errorf("%w until %s", errCircuitOpen, breaker.openUntil.UTC().Format(time.RFC3339))
+	}
+	if now.Before(breaker.nextAttempt) {
+		return fmt.Errorf("%w until %s", errBackingOff, breaker.nextAttempt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// recordFetchSuccess closes the circuit and resets the backoff
+func recordFetchSuccess() {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	if breaker.failures >= breakerThreshold {
+		logger.Log.Info().Msg("Upstream recovered, closing circuit breaker")
+	}
+	breaker.failures = 0
+	breaker.nextAttempt = time.Time{}
+	breaker.openUntil = time.Time{}
+	metrics.UpstreamFailures.Set(0)
+}
+
+// recordFetchFailure schedules the next allowed attempt with exponential
+// backoff and jitter, opening the circuit after repeated failures
+func recordFetchFailure(now time.Time) {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	breaker.failures++
+	metrics.UpstreamFailures.Set(float64(breaker.failures))
+
+	if breaker.failures >= breakerThreshold {
+		breaker.openUntil = now.Add(breakerCooldown)
+		breaker.nextAttempt = breaker.openUntil
+		logger.Log.Warn().
+			Int("failures", breaker.failures).
+			Time("until", breaker.openUntil).
+			Msg("Opening upstream circuit breaker")
+		return
+	}
+
+	delay := backoffBase << (breaker.failures - 1)
+	if delay > backoffMax || delay <= 0 {
+		delay = backoffMax
+	}
+	breaker.nextAttempt = now.Add(jitter(delay))
+	logger.Log.Info().
+		Int("failures", breaker.failures).
+		Time("nextAttempt", breaker.nextAttempt).
+		Msg("Backing off upstream fetch")
+}
+
+// retryIn returns how long until the upstream may be contacted again
+func retryIn(now time.Time) time.Duration {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	if wait := breaker.nextAttempt.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
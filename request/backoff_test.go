@@ -0,0 +1,159 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedUpstream replays a fixed sequence of status codes and records the
+// conditional headers it received
+type scriptedUpstream struct {
+	sync.Mutex
+	statuses    []int
+	feed        []byte
+	hits        int
+	ifNoneMatch []string
+	ifModSince  []string
+}
+
+func (s *scriptedUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.hits++
+	s.ifNoneMatch = append(s.ifNoneMatch, r.Header.Get("If-None-Match"))
+	s.ifModSince = append(s.ifModSince, r.Header.Get("If-Modified-Since"))
+
+	status := http.StatusInternalServerError
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+
+	switch status {
+	case http.StatusOK:
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 04 Feb 2026 06:00:00 GMT")
+		w.Write(s.feed)
+	default:
+		w.WriteHeader(status)
+	}
+}
+
+func (s *scriptedUpstream) hitCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.hits
+}
+
+func TestConditionalFetchAndBackoff(t *testing.T) {
+	upstream := &scriptedUpstream{
+		feed: rawFeed(t),
+		statuses: []int{
+			http.StatusOK,
+			http.StatusNotModified,
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+			http.StatusInternalServerError,
+			http.StatusOK,
+		},
+	}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	resetRaceCache(t, server.URL)
+	previousJitter := jitter
+	jitter = func(d time.Duration) time.Duration { return d }
+	t.Cleanup(func() { jitter = previousJitter })
+
+	ctx := context.Background()
+
+	// 200: races and validators are stored
+	races, err := updateTizCache(ctx)
+	if err != nil {
+		t.Fatalf("Initial fetch failed: %v", err)
+	}
+	if raceCache.ETag != `"v1"` {
+		t.Errorf("Expected ETag to be stored, got %q", raceCache.ETag)
+	}
+
+	// 304: the cached races are still valid and the fetch time moves
+	raceCache.Lock()
+	raceCache.LastFetch = time.Now().Add(-cacheTTL)
+	raceCache.Unlock()
+
+	revalidated, err := updateTizCache(ctx)
+	if err != nil {
+		t.Fatalf("Conditional fetch failed: %v", err)
+	}
+	if len(revalidated) != len(races) {
+		t.Errorf("Expected %d cached races after 304, got %d", len(races), len(revalidated))
+	}
+	if age, _ := SnapshotAge(); age > time.Minute {
+		t.Errorf("Expected a 304 to refresh the snapshot age, got %s", age)
+	}
+	if upstream.ifNoneMatch[1] != `"v1"` || upstream.ifModSince[1] == "" {
+		t.Errorf("Expected conditional headers on revalidation, got %q / %q", upstream.ifNoneMatch[1], upstream.ifModSince[1])
+	}
+
+	// 500: exponential backoff blocks the next attempts without contacting the upstream
+	for failure := 1; failure < breakerThreshold; failure++ {
+		now := time.Now()
+		if _, err := updateTizCache(ctx); err == nil {
+			t.Fatalf("Expected failure %d on a 500 response", failure)
+		}
+
+		expected := backoffBase << (failure - 1)
+		if wait := retryIn(now); wait < expected-time.Second || wait > expected+time.Second {
+			t.Errorf("Failure %d: expected backoff of %s, got %s", failure, expected, wait)
+		}
+
+		hits := upstream.hitCount()
+		if _, err := updateTizCache(ctx); !errors.Is(err, errBackingOff) {
+			t.Errorf("Failure %d: expected errBackingOff, got %v", failure, err)
+		}
+		if upstream.hitCount() != hits {
+			t.Errorf("Failure %d: upstream contacted while backing off", failure)
+		}
+
+		breaker.Lock()
+		breaker.nextAttempt = time.Time{}
+		breaker.Unlock()
+	}
+
+	// The last failure opens the circuit
+	if _, err := updateTizCache(ctx); err == nil {
+		t.Fatal("Expected the threshold failure")
+	}
+	hits := upstream.hitCount()
+	if _, err := updateTizCache(ctx); !errors.Is(err, errCircuitOpen) {
+		t.Errorf("Expected errCircuitOpen, got %v", err)
+	}
+	if upstream.hitCount() != hits {
+		t.Error("Upstream contacted while the circuit is open")
+	}
+
+	// The last known good races survive the outage
+	if status := GetCacheStatus(); status.RaceCount != len(races) {
+		t.Errorf("Expected %d cached races during the outage, got %d", len(races), status.RaceCount)
+	}
+
+	// After the cooldown a trial fetch closes the circuit again
+	breaker.Lock()
+	breaker.openUntil = time.Now().Add(-time.Second)
+	breaker.nextAttempt = breaker.openUntil
+	breaker.Unlock()
+
+	if _, err := updateTizCache(ctx); err != nil {
+		t.Fatalf("Trial fetch failed: %v", err)
+	}
+	if err := allowFetch(time.Now()); err != nil {
+		t.Errorf("Expected the circuit to be closed after recovery, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/logger"
+	"cpe/calendar/metrics"
+	"cpe/calendar/types"
+	"sync"
+	"time"
+)
+
+var (
+	// cacheTTL is how long a Tiz snapshot is considered fresh
+	cacheTTL = 24 * time.Hour
+	// refreshAhead is how long before expiry the background refresher re-fetches
+	refreshAhead = 1 * time.Hour
+	// fetchTimeout bounds a single upstream fetch
+	fetchTimeout = 30 * time.Second
+
+	raceCache struct {
+		sync.RWMutex
+		Races        []types.TizRace
+		RawHTML      string
+		ETag         string
+		LastModified string
+		LastFetch    time.Time
+		LastAttempt  time.Time
+		LastError    error
+	}
+)
+
+// CacheStatus describes the state of the Tiz snapshot
+type CacheStatus struct {
+	LastFetch   time.Time // Time of the last successful fetch
+	LastAttempt time.Time // Time of the last fetch attempt
+	LastError   error     // Error of the last attempt, nil if it succeeded
+	RaceCount   int
+}
+
+// GetCacheStatus returns the current state of the Tiz snapshot
+func GetCacheStatus() CacheStatus {
+	raceCache.RLock()
+	defer raceCache.RUnlock()
+
+	return CacheStatus{
+		LastFetch:   raceCache.LastFetch,
+		LastAttempt: raceCache.LastAttempt,
+		LastError:   raceCache.LastError,
+		RaceCount:   len(raceCache.Races),
+	}
+}
+
+// GetTizRaces returns the Tiz races with stale-while-revalidate caching.
+// The last snapshot is always served immediately; an expired snapshot
+// triggers a background refresh. Callers only wait on the upstream when
+// no snapshot has ever been fetched. A failed refresh never discards the
+// last known good snapshot, however old it is.
+func GetTizRaces(ctx context.Context) ([]types.TizRace, error) {
+	raceCache.RLock()
+	if !raceCache.LastFetch.IsZero() {
+		races := make([]types.TizRace, len(raceCache.Races))
+		copy(races, raceCache.Races)
+		age := time.Since(raceCache.LastFetch)
+		raceCache.RUnlock()
+
+		if age >= cacheTTL {
+			logger.Log.Info().Dur("age", age).Msg("Returning stale Tiz race data, revalidating in background")
+			startRefresh()
+		} else {
+			logger.Log.Info().Msg("Returning cached Tiz race data")
+		}
+		return races, nil
+	}
+	raceCache.RUnlock()
+
+	logger.Log.Info().Msg("No Tiz snapshot yet, fetching synchronously")
+	return refreshTizRaces(ctx)
+}
+
+// SnapshotAge returns the age of the current Tiz snapshot, and false when
+// no snapshot has been fetched yet
+func SnapshotAge() (time.Duration, bool) {
+	raceCache.RLock()
+	defer raceCache.RUnlock()
+
+	if raceCache.LastFetch.IsZero() {
+		return 0, false
+	}
+	return time.Since(raceCache.LastFetch), true
+}
+
+// refreshLoop re-fetches the Tiz schedule ahead of cache expiry until ctx
+// is cancelled
+func refreshLoop(ctx context.Context) {
+	for {
+		wait := nextRefreshIn()
+		logger.Log.Debug().Dur("in", wait).Msg("Scheduled next Tiz refresh")
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		if _, err := refreshTizRaces(fetchCtx); err != nil {
+			// Wait for the backoff to allow the next attempt
+			wait := retryIn(time.Now())
+			if wait < backoffBase {
+				wait = backoffBase
+			}
+			logger.Log.Warn().Err(err).Dur("retryIn", wait).Msg("Background Tiz refresh failed")
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+		cancel()
+		prepareRaces(ctx)
+	}
+}
+
+// nextRefreshIn returns how long the refresher should wait before fetching
+func nextRefreshIn() time.Duration {
+	age, ok := SnapshotAge()
+	if !ok {
+		return 0
+	}
+
+	wait := cacheTTL - refreshAhead - age
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// updateTizCache fetches the upstream schedule and stores it in the cache.
+// On failure the previous snapshot is kept and the error is recorded.
+// Callers go through refreshTizRaces so concurrent refreshes are coalesced.
+func updateTizCache(ctx context.Context) ([]types.TizRace, error) {
+	now := time.Now()
+	if err := allowFetch(now); err != nil {
+		logger.Log.Info().Err(err).Msg("Skipping Tiz refresh")
+		return nil, err
+	}
+
+	// Only revalidate when there is a cached copy to fall back on
+	raceCache.RLock()
+	var etag, lastModified string
+	if len(raceCache.Races) > 0 {
+		etag, lastModified = raceCache.ETag, raceCache.LastModified
+	}
+	raceCache.RUnlock()
+
+	resp, err := fetchTizRaces(ctx, etag, lastModified)
+	now = time.Now()
+	if err != nil {
+		recordFetchFailure(now)
+
+		raceCache.Lock()
+		raceCache.LastAttempt = now
+		raceCache.LastError = err
+		hasSnapshot := !raceCache.LastFetch.IsZero()
+		raceCache.Unlock()
+
+		if hasSnapshot {
+			logger.Log.Warn().Err(err).Msg("Tiz refresh failed, keeping last known good snapshot")
+		}
+		return nil, err
+	}
+	recordFetchSuccess()
+
+	raceCache.Lock()
+	previous := raceCache.Races
+	if !resp.NotModified {
+		raceCache.Races = resp.Races
+		raceCache.RawHTML = resp.RawHTML
+		raceCache.ETag = resp.ETag
+		raceCache.LastModified = resp.LastModified
+	}
+	raceCache.LastFetch = now
+	raceCache.LastAttempt = now
+	raceCache.LastError = nil
+	sourcesRefreshed()
+	snapshot := Snapshot{
+		FetchedAt:    now,
+		RawHTML:      raceCache.RawHTML,
+		ETag:         raceCache.ETag,
+		LastModified: raceCache.LastModified,
+		Races:        raceCache.Races,
+	}
+	raceCache.Unlock()
+
+	metrics.SnapshotTimestamp.Set(float64(now.Unix()))
+
+	if err := saveSnapshot(snapshot); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to persist Tiz snapshot")
+	}
+	if !resp.NotModified {
+		recordSnapshot(previous, snapshot)
+	}
+
+	return snapshot.Races, nil
+}
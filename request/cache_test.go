@@ -0,0 +1,258 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resetRaceCache clears the package cache and points the fetcher at url
+func resetRaceCache(t *testing.T, url string) {
+	t.Helper()
+
+	previousURL, previousDir := tizURL, dataDir
+	tizURL, dataDir = url, t.TempDir()
+	t.Cleanup(func() { tizURL, dataDir = previousURL, previousDir })
+
+	raceCache.Lock()
+	raceCache.Races = nil
+	raceCache.RawHTML = ""
+	raceCache.LastFetch = time.Time{}
+	raceCache.LastAttempt = time.Time{}
+	raceCache.LastError = nil
+	raceCache.ETag = ""
+	raceCache.LastModified = ""
+	raceCache.Unlock()
+	sourcesRefreshed()
+
+	resetBreaker()
+}
+
+// resetBreaker forgets previous upstream failures
+func resetBreaker() {
+	breaker.Lock()
+	breaker.failures = 0
+	breaker.nextAttempt = time.Time{}
+	breaker.openUntil = time.Time{}
+	breaker.Unlock()
+}
+
+// rawFeed returns the captured feed from testdata
+func rawFeed(t *testing.T) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "corpus", "2026-02-04.html"))
+	if err != nil {
+		t.Fatalf("Failed to read feed: %v", err)
+	}
+	return content
+}
+
+func TestGetTizRacesServesStaleSnapshot(t *testing.T) {
+	feed := rawFeed(t)
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write(feed)
+	}))
+	defer server.Close()
+
+	resetRaceCache(t, server.URL)
+	raceCache.Lock()
+	raceCache.Races = []types.TizRace{{Name: "Stale race"}}
+	raceCache.LastFetch = time.Now().Add(-cacheTTL - time.Hour)
+	raceCache.Unlock()
+
+	start := time.Now()
+	races, err := GetTizRaces(context.Background())
+	if err != nil {
+		t.Fatalf("GetTizRaces returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Stale snapshot should be served immediately, took %s", elapsed)
+	}
+	if len(races) != 1 || races[0].Name != "Stale race" {
+		t.Errorf("Expected the stale snapshot, got %+v", races)
+	}
+
+	// The background revalidation must reach the upstream
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&hits) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected one background fetch, got %d", hits)
+	}
+
+	if age, ok := SnapshotAge(); !ok || age < cacheTTL {
+		t.Errorf("Expected snapshot age beyond TTL while revalidating, got %s", age)
+	}
+
+	close(release)
+	waitForRefresh(t)
+	if age, _ := SnapshotAge(); age >= cacheTTL {
+		t.Errorf("Expected a fresh snapshot after revalidation, got age %s", age)
+	}
+}
+
+// waitForRefresh blocks until the background refresh has completed
+func waitForRefresh(t *testing.T) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		inflight.Lock()
+		refreshing := inflight.call != nil
+		inflight.Unlock()
+		if !refreshing {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Background refresh did not complete")
+}
+
+func TestNextRefreshIn(t *testing.T) {
+	resetRaceCache(t, tizURL)
+	if wait := nextRefreshIn(); wait != 0 {
+		t.Errorf("Expected immediate refresh without snapshot, got %s", wait)
+	}
+
+	raceCache.Lock()
+	raceCache.LastFetch = time.Now()
+	raceCache.Unlock()
+
+	wait := nextRefreshIn()
+	if wait <= 0 || wait > cacheTTL-refreshAhead {
+		t.Errorf("Expected refresh ahead of expiry, got %s", wait)
+	}
+}
+
+func TestRefreshKeepsLastKnownGood(t *testing.T) {
+	feed := rawFeed(t)
+	var status int32 = http.StatusOK
+	var empty int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		if atomic.LoadInt32(&empty) == 0 {
+			w.Write(feed)
+		} else {
+			w.Write([]byte("<ul><li>Maintenance</li></ul>"))
+		}
+	}))
+	defer server.Close()
+
+	resetRaceCache(t, server.URL)
+	good, err := refreshTizRaces(context.Background())
+	if err != nil {
+		t.Fatalf("Initial refresh failed: %v", err)
+	}
+	if (TizSource{}).Warning() != "" {
+		t.Error("Expected no warning after a successful refresh")
+	}
+
+	// Upstream error
+	atomic.StoreInt32(&status, http.StatusInternalServerError)
+	if _, err := refreshTizRaces(context.Background()); err == nil {
+		t.Fatal("Expected refresh to fail on a 500 response")
+	}
+
+	// Empty parse
+	resetBreaker()
+	atomic.StoreInt32(&status, http.StatusOK)
+	atomic.StoreInt32(&empty, 1)
+	if _, err := refreshTizRaces(context.Background()); err != errNoRaces {
+		t.Fatalf("Expected errNoRaces on an empty schedule, got %v", err)
+	}
+
+	// Expire the snapshot: it must still be served, with a warning
+	raceCache.Lock()
+	raceCache.LastFetch = time.Now().Add(-2 * cacheTTL)
+	raceCache.Unlock()
+	resetBreaker()
+
+	races, err := GetTizRaces(context.Background())
+	if err != nil {
+		t.Fatalf("GetTizRaces returned error: %v", err)
+	}
+	if len(races) != len(good) {
+		t.Errorf("Expected the last known good %d races, got %d", len(good), len(races))
+	}
+	waitForRefresh(t)
+	if (TizSource{}).Warning() == "" {
+		t.Error("Expected a warning when serving the last known good snapshot")
+	}
+}
+
+func TestConcurrentMissesShareOneFetch(t *testing.T) {
+	feed := rawFeed(t)
+	var hits int32
+	var status int32 = http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		// Keep the fetch in flight long enough for every caller to join it
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write(feed)
+	}))
+	defer server.Close()
+
+	const callers = 50
+	run := func() ([]int, []error) {
+		counts := make([]int, callers)
+		errs := make([]error, callers)
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				races, err := GetTizRaces(context.Background())
+				counts[i], errs[i] = len(races), err
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		return counts, errs
+	}
+
+	// Every caller shares the error of a failing fetch
+	resetRaceCache(t, server.URL)
+	atomic.StoreInt32(&status, http.StatusBadGateway)
+	_, errs := run()
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected a single upstream fetch for failing misses, got %d", got)
+	}
+	for i, err := range errs {
+		if err == nil {
+			t.Fatalf("Caller %d expected the shared error", i)
+		}
+	}
+
+	// Every caller shares the races of a successful fetch
+	resetBreaker()
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&status, http.StatusOK)
+	counts, errs := run()
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected a single upstream fetch for concurrent misses, got %d", got)
+	}
+	for i := range counts {
+		if errs[i] != nil {
+			t.Fatalf("Caller %d returned error: %v", i, errs[i])
+		}
+		if counts[i] == 0 || counts[i] != counts[0] {
+			t.Errorf("Caller %d got %d races, expected %d", i, counts[i], counts[0])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	cancellationsVersion  = 1
+	cancellationsFilename = "cancellations.json"
+)
+
+var (
+	// cancelledRetention is how long a cancelled race is still served once the
+	// feed drops it, so calendar clients see the cancellation
+	cancelledRetention = 7 * 24 * time.Hour
+
+	cancellations = struct {
+		sync.Mutex
+		races map[string]cancelledRace // By UID
+	}{races: map[string]cancelledRace{}}
+)
+
+// cancelledRace is the last version served of a cancelled race
+type cancelledRace struct {
+	Race      types.TizRace `json:"race"`
+	Cancelled time.Time     `json:"cancelled"`         // When the race was first served cancelled
+	Dropped   time.Time     `json:"dropped,omitempty"` // When the feed dropped the race, zero while it lists it
+}
+
+// cancellationsFile is the on-disk representation of the cancelled races
+type cancellationsFile struct {
+	Version int                      `json:"version"`
+	Races   map[string]cancelledRace `json:"races"`
+}
+
+// LoadCancellations restores the cancelled races from the data directory.
+// Missing cancellations are not an error.
+func LoadCancellations() error {
+	if dataDir == "" {
+		return nil
+	}
+
+	path := filepath.Join(dataDir, cancellationsFilename)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cancellations: %w", err)
+	}
+
+	var file cancellationsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("failed to decode cancellations: %w", err)
+	}
+	if file.Version != cancellationsVersion {
+		return fmt.Errorf("unsupported cancellations version %d (expected %d)", file.Version, cancellationsVersion)
+	}
+
+	cancellations.Lock()
+	cancellations.races = file.Races
+	if cancellations.races == nil {
+		cancellations.races = map[string]cancelledRace{}
+	}
+	cancellations.Unlock()
+
+	logger.Log.Info().Str("path", path).Int("count", len(file.Races)).Msg("Loaded cancelled races from disk")
+	return nil
+}
+
+// retainCancelled remembers the cancelled races of the schedule and adds
+// back those the feed dropped less than cancelledRetention ago, so calendar
+// clients remove them rather than keep a stale event. Races are matched by
+// UID, which must be assigned. Runs once per refresh of the sources, which
+// is when the cancelled races are written to disk.
+func retainCancelled(races []types.TizRace, now time.Time) []types.TizRace {
+	now = now.UTC().Truncate(time.Second)
+
+	cancellations.Lock()
+	defer cancellations.Unlock()
+
+	dirty := false
+	present := make(map[string]bool, len(races))
+	for _, race := range races {
+		present[race.UID] = true
+		entry, ok := cancellations.races[race.UID]
+		switch {
+		case race.Status == types.StatusCancelled:
+			if !ok {
+				entry.Cancelled = now
+				logger.Log.Info().Str("uid", race.UID).Str("name", race.Name).Str("status", race.StatusNote).Msg("Race cancelled")
+			}
+			race.RawHTML = ""
+			if !ok || !entry.Dropped.IsZero() || raceFingerprint(entry.Race) != raceFingerprint(race) {
+				dirty = true
+			}
+			entry.Race, entry.Dropped = race, time.Time{}
+			cancellations.races[race.UID] = entry
+		case ok:
+			// Reinstated
+			delete(cancellations.races, race.UID)
+			dirty = true
+		}
+	}
+
+	var retained []types.TizRace
+	for uid, entry := range cancellations.races {
+		if present[uid] {
+			continue
+		}
+		if entry.Dropped.IsZero() {
+			entry.Dropped = now
+			cancellations.races[uid] = entry
+			dirty = true
+		}
+		if now.Sub(entry.Dropped) > cancelledRetention {
+			delete(cancellations.races, uid)
+			dirty = true
+			continue
+		}
+		race := entry.Race
+		race.UID = uid
+		retained = append(retained, race)
+	}
+	sort.Slice(retained, func(i, j int) bool {
+		if retained[i].StartDate != retained[j].StartDate {
+			return retained[i].StartDate < retained[j].StartDate
+		}
+		return retained[i].UID < retained[j].UID
+	})
+	races = append(races, retained...)
+
+	if !dirty || dataDir == "" {
+		return races
+	}
+
+	content, err := json.Marshal(cancellationsFile{Version: cancellationsVersion, Races: cancellations.races})
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode cancelled races")
+		return races
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, cancellationsFilename), content); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to persist cancelled races")
+	}
+	return races
+}
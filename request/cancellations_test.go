@@ -0,0 +1,159 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/types"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// resetCancellations forgets the cancelled races
+func resetCancellations(t *testing.T) {
+	t.Helper()
+
+	cancellations.Lock()
+	cancellations.races = map[string]cancelledRace{}
+	cancellations.Unlock()
+	t.Cleanup(func() {
+		cancellations.Lock()
+		cancellations.races = map[string]cancelledRace{}
+		cancellations.Unlock()
+	})
+}
+
+// serveCancelled serves races as GetRaces does, cancelled races included
+func serveCancelled(races []types.TizRace, now time.Time) []types.TizRace {
+	assignUIDs(races, now)
+	races = retainCancelled(races, now)
+	applyRevisions(races, now)
+	return races
+}
+
+func TestRetainCancelled(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+
+	now := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	race := types.TizRace{Name: "Strade Bianche", StartDate: "2026-03-07", EndDate: "2026-03-07", Categories: []string{"ME"}, Status: types.StatusConfirmed}
+	other := types.TizRace{Name: "Paris-Nice", StartDate: "2026-03-08", EndDate: "2026-03-15", Status: types.StatusConfirmed}
+
+	served := serveCancelled([]types.TizRace{race, other}, now)
+	uid, sequence := served[0].UID, served[0].Revision.Sequence
+
+	cancelled := race
+	cancelled.Status, cancelled.StatusNote = types.StatusCancelled, "Cancelled"
+	served = serveCancelled([]types.TizRace{cancelled, other}, now.Add(time.Hour))
+	if served[0].UID != uid || served[0].Revision.Sequence != sequence+1 {
+		t.Fatalf("Expected the cancellation to bump the sequence of %s, got %s %+v", uid, served[0].UID, served[0].Revision)
+	}
+
+	// The feed drops the race: it is still served cancelled, unchanged
+	served = serveCancelled([]types.TizRace{other}, now.Add(24*time.Hour))
+	if len(served) != 2 || served[1].UID != uid || served[1].Status != types.StatusCancelled {
+		t.Fatalf("Expected the dropped race to be served cancelled, got %+v", served)
+	}
+	if served[1].Revision.Sequence != sequence+1 {
+		t.Errorf("Expected the sequence to be kept, got %d", served[1].Revision.Sequence)
+	}
+
+	// Restored after a restart
+	cancellations.Lock()
+	cancellations.races = map[string]cancelledRace{}
+	cancellations.Unlock()
+	if err := LoadCancellations(); err != nil {
+		t.Fatalf("LoadCancellations returned error: %v", err)
+	}
+	served = serveCancelled([]types.TizRace{other}, now.Add(48*time.Hour))
+	if len(served) != 2 || served[1].UID != uid {
+		t.Fatalf("Expected the cancelled race to survive a restart, got %+v", served)
+	}
+
+	// Retention is counted from the day the feed dropped the race
+	dropped := now.Add(24 * time.Hour)
+	if served = serveCancelled([]types.TizRace{other}, dropped.Add(cancelledRetention)); len(served) != 2 {
+		t.Errorf("Expected the cancelled race to be served until the end of the retention, got %+v", served)
+	}
+	if served = serveCancelled([]types.TizRace{other}, dropped.Add(cancelledRetention+time.Second)); len(served) != 1 {
+		t.Errorf("Expected the cancelled race to be dropped after the retention, got %+v", served)
+	}
+}
+
+func TestRetainCancelledFromDrop(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+
+	now := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	race := types.TizRace{Name: "Strade Bianche", StartDate: "2026-03-07", EndDate: "2026-03-07", Status: types.StatusCancelled, StatusNote: "Cancelled"}
+	serveCancelled([]types.TizRace{race}, now)
+
+	// Listed cancelled for longer than the retention, then dropped
+	listed := now.Add(2 * cancelledRetention)
+	serveCancelled([]types.TizRace{race}, listed)
+	if served := serveCancelled(nil, listed.Add(time.Hour)); len(served) != 1 || served[0].Status != types.StatusCancelled {
+		t.Fatalf("Expected the race to be served cancelled once dropped, got %+v", served)
+	}
+	if served := serveCancelled(nil, listed.Add(time.Hour+cancelledRetention)); len(served) != 1 {
+		t.Errorf("Expected the race to be served for the retention after the drop, got %+v", served)
+	}
+	if served := serveCancelled(nil, listed.Add(2*time.Hour+cancelledRetention)); len(served) != 0 {
+		t.Errorf("Expected the race to be dropped after the retention, got %+v", served)
+	}
+}
+
+func TestRetainCancelledForgetsReinstated(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+
+	now := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	race := types.TizRace{Name: "Omloop Nieuwsblad", StartDate: "2026-02-28", EndDate: "2026-02-28", Status: types.StatusCancelled, StatusNote: "Postponed"}
+	serveCancelled([]types.TizRace{race}, now)
+
+	race.Status, race.StatusNote = types.StatusConfirmed, ""
+	serveCancelled([]types.TizRace{race}, now.Add(time.Hour))
+
+	if served := serveCancelled(nil, now.Add(2*time.Hour)); len(served) != 0 {
+		t.Errorf("Expected a reinstated race not to be served once dropped, got %+v", served)
+	}
+}
+
+func TestRetainCancelledOncePerRefresh(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+	resetOverrides(t, "")
+
+	activeSources.RLock()
+	previous := activeSources.sources
+	activeSources.RUnlock()
+	t.Cleanup(func() { SetSources(previous) })
+
+	race := types.TizRace{Name: "Strade Bianche", StartDate: "2026-03-07", EndDate: "2026-03-07", Status: types.StatusCancelled, StatusNote: "Cancelled"}
+	source := staticSource{name: "static", races: []types.TizRace{race}}
+	SetSources([]RaceSource{source})
+	serveRaces := func() []types.TizRace {
+		races, _, err := GetRaces(context.Background())
+		if err != nil {
+			t.Fatalf("GetRaces returned error: %v", err)
+		}
+		return races
+	}
+	serveRaces()
+
+	// Dropped by the feed: recorded at the refresh, not by the requests
+	SetSources([]RaceSource{staticSource{name: "static"}})
+	if races := serveRaces(); len(races) != 1 || races[0].Status != types.StatusCancelled {
+		t.Fatalf("Expected the dropped race to be served cancelled, got %+v", races)
+	}
+	path := filepath.Join(dataDir, cancellationsFilename)
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Expected the cancelled races to be persisted: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		serveRaces()
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected requests not to write the cancelled races, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const classificationsVersion = 1
+
+var (
+	// bundledClassifications is the dataset used when no other is configured
+	//go:embed uci_classifications.json
+	bundledClassifications []byte
+
+	// classificationsPath is the dataset replacing the bundled one, empty for the bundled one
+	classificationsPath = ""
+
+	classifications = struct {
+		sync.RWMutex
+		entries []classificationEntry
+	}{}
+
+	// yearPattern matches the edition years written in race names
+	yearPattern = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	// womenPattern tells a women's race from its name
+	womenPattern = regexp.MustCompile(`(?i)\b(?:women|women's|femmes|feminas|femenina|donne|dames|ladies)\b`)
+)
+
+// classificationEntry is a race of the dataset
+type classificationEntry struct {
+	Name       string   `json:"name"`
+	Class      string   `json:"class"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Categories []string `json:"categories,omitempty"` // WE or ME, empty for both
+	slugs      []string // Of the name and aliases
+}
+
+// classificationsFile is the on-disk representation of the dataset
+type classificationsFile struct {
+	Version int                   `json:"version"`
+	Season  int                   `json:"season"`
+	Races   []classificationEntry `json:"races"`
+}
+
+// LoadClassifications reads the UCI classification dataset, the configured
+// one or the bundled one
+func LoadClassifications() error {
+	content, source := bundledClassifications, "bundled"
+	if classificationsPath != "" {
+		var err error
+		if content, err = os.ReadFile(classificationsPath); err != nil {
+			return fmt.Errorf("failed to read UCI classifications: %w", err)
+		}
+		source = classificationsPath
+	}
+
+	file, err := parseClassifications(content)
+	if err != nil {
+		return fmt.Errorf("invalid UCI classifications %s: %w", source, err)
+	}
+
+	classifications.Lock()
+	classifications.entries = file.Races
+	classifications.Unlock()
+
+	logger.Log.Info().Str("source", source).Int("season", file.Season).Int("count", len(file.Races)).Msg("Loaded UCI classifications")
+	return nil
+}
+
+// parseClassifications decodes and validates a dataset
+func parseClassifications(content []byte) (classificationsFile, error) {
+	var file classificationsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return file, fmt.Errorf("failed to decode: %w", err)
+	}
+	if file.Version != classificationsVersion {
+		return file, fmt.Errorf("unsupported version %d (expected %d)", file.Version, classificationsVersion)
+	}
+
+	var errs []error
+	for i, entry := range file.Races {
+		if strings.TrimSpace(entry.Name) == "" {
+			errs = append(errs, fmt.Errorf("race %d has no name", i))
+		}
+		if _, ok := types.ClassificationTier(entry.Class); !ok {
+			errs = append(errs, fmt.Errorf("race %q has an unknown class %q", entry.Name, entry.Class))
+		}
+		for _, category := range entry.Categories {
+			if category != "WE" && category != "ME" {
+				errs = append(errs, fmt.Errorf("race %q has an unknown category %q (expected WE or ME)", entry.Name, category))
+			}
+		}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			file.Races[i].slugs = append(file.Races[i].slugs, classificationSlug(name))
+		}
+	}
+
+	return file, errors.Join(errs...)
+}
+
+// classifyRace returns the UCI class of the race of the dataset matching
+// race, or "" when none does. A dataset name matches when the race name
+// contains it, so "Tour de France stage 5" is the Tour de France, or is
+// nearly identical to the start of the race name, so a typo in the feed
+// does not lose the class. The longest match wins, and races of the other
+// gender are skipped.
+func classifyRace(race types.TizRace) string {
+	classifications.RLock()
+	defer classifications.RUnlock()
+
+	slug := classificationSlug(race.Name)
+	gender := raceGender(race)
+
+	best, bestLength := "", 0
+	fuzzy, fuzzyDistance := "", -1
+	for _, entry := range classifications.entries {
+		if gender != "" && len(entry.Categories) > 0 && !containsString(entry.Categories, gender) {
+			continue
+		}
+		for _, candidate := range entry.slugs {
+			if containsWords(slug, candidate) {
+				if len(candidate) > bestLength {
+					best, bestLength = entry.Class, len(candidate)
+				}
+				continue
+			}
+			if len(slug) < len(candidate) {
+				continue
+			}
+			prefix := slug[:len(candidate)]
+			// One edit per ten letters, at most two: Tour of Brittany is not Tour of Britain
+			distance := editDistance(prefix, candidate)
+			if distance <= 2 && distance*10 <= len(candidate) && (fuzzyDistance < 0 || distance < fuzzyDistance) {
+				fuzzy, fuzzyDistance = entry.Class, distance
+			}
+		}
+	}
+
+	if best != "" {
+		return best
+	}
+	return fuzzy
+}
+
+// raceGender returns WE or ME from the categories or the name of a race,
+// "" when it is unknown or mixed
+func raceGender(race types.TizRace) string {
+	women, men := womenPattern.MatchString(race.Name), false
+	for _, category := range race.Categories {
+		switch category {
+		case "WE", "Women Elite", "Women":
+			women = true
+		case "ME", "Men Elite", "Men":
+			men = true
+		}
+	}
+	switch {
+	case women && !men:
+		return "WE"
+	case men && !women:
+		return "ME"
+	}
+	return ""
+}
+
+// classificationSlug normalises a race name for matching, dropping the
+// edition year
+func classificationSlug(name string) string {
+	return slugify(yearPattern.ReplaceAllString(name, ""))
+}
+
+// containsWords tells whether the slug candidate is found in slug starting at
+// a word. It may end inside a word, as the feed glues the stage to the name.
+func containsWords(slug, candidate string) bool {
+	for i := 0; i+len(candidate) <= len(slug); i++ {
+		if (i == 0 || slug[i-1] == '-') && slug[i:i+len(candidate)] == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassifyRace(t *testing.T) {
+	if err := LoadClassifications(); err != nil {
+		t.Fatalf("Bundled UCI classifications are invalid: %v", err)
+	}
+
+	tests := []struct {
+		race types.TizRace
+		want string
+	}{
+		{types.TizRace{Name: "Tour de Francestage 5 (of 21)", Categories: []string{"ME"}}, "2.UWT"},
+		{types.TizRace{Name: "Tour de France Femmes", Categories: []string{"WE"}}, "2.WWT"},
+		{types.TizRace{Name: "UAE Tour Womenstage 1 (of 4)", Categories: []string{"WE"}}, "2.WWT"},
+		{types.TizRace{Name: "UAE Tour Women"}, "2.WWT"},
+		{types.TizRace{Name: "Volta Comunitat Valencianastage 1 (of 5)", Categories: []string{"ME"}}, "2.Pro"},
+		{types.TizRace{Name: "Volta Comunitat Valencianna", Categories: []string{"ME"}}, "2.Pro"},
+		{types.TizRace{Name: "Etoile de Bessèges - Tour du Gard", Categories: []string{"ME"}}, "2.1"},
+		{types.TizRace{Name: "Muscat Classic", Categories: []string{"ME"}}, "1.1"},
+		{types.TizRace{Name: "Liège–Bastogne–Liège 2026"}, "1.UWT"},
+		{types.TizRace{Name: "Tour of Flanders", Categories: []string{"ME"}}, "1.UWT"},
+		{types.TizRace{Name: "X2O Badkamers Trofee Lille - Krawatencross 2026", Categories: []string{"WE", "ME"}}, "C1"},
+		{types.TizRace{Name: "UCI Track Nations Cup Konya", Stage: "day 2 (of 3)"}, "CDM"},
+		{types.TizRace{Name: "Tour of Brittany", Categories: []string{"ME"}}, ""},
+		{types.TizRace{Name: "Tour of Oman Women", Categories: []string{"WE"}}, ""},
+		{types.TizRace{Name: "San Silvestre Ciclista", Categories: []string{"ME"}}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := classifyRace(tt.race); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.race.Name, tt.want, got)
+		}
+	}
+}
+
+func TestLoadClassificationsRejectsInvalid(t *testing.T) {
+	previous := classificationsPath
+	t.Cleanup(func() {
+		classificationsPath = previous
+		LoadClassifications()
+	})
+
+	classificationsPath = filepath.Join(t.TempDir(), "uci.json")
+	content := `{"version": 1, "races": [{"name": "Tour de France", "class": "2.HC"}, {"name": "", "class": "CDM", "categories": ["U23"]}]}`
+	if err := os.WriteFile(classificationsPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := LoadClassifications()
+	if err == nil {
+		t.Fatal("Expected an invalid dataset to be rejected")
+	}
+	for _, want := range []string{`unknown class "2.HC"`, "race 1 has no name", `unknown category "U23"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package request
+
+import (
+	"cpe/calendar/config"
+)
+
+// Configure applies the runtime configuration to the request package and
+// selects the active race sources
+func Configure(cfg config.Config) error {
+	tizURL = cfg.UpstreamURL
+	userAgent = cfg.UserAgent
+	cacheTTL = cfg.CacheTTL
+	refreshAhead = cfg.RefreshAhead
+	fetchTimeout = cfg.HTTPTimeout
+	dataDir = cfg.DataDir
+	replayPath = cfg.ReplayPath
+	classificationsPath = cfg.UCIClassifications
+	overridesPath = cfg.OverridesPath
+	cancelledRetention = cfg.CancelledRetention
+
+	if err := LoadClassifications(); err != nil {
+		return err
+	}
+	if err := LoadOverrides(); err != nil {
+		return err
+	}
+
+	sources, err := NewSources(cfg.Sources)
+	if err != nil {
+		return err
+	}
+	SetSources(sources)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the parser corpus")
+
+// corpusDir holds captured feeds named after the day they were fetched
+// (2026-02-04.html), each with the races it must parse to (2026-02-04.golden.json)
+var corpusDir = filepath.Join("testdata", "corpus")
+
+// corpusFeeds returns the paths of the feeds of the corpus
+func corpusFeeds(t testing.TB) []string {
+	feeds, err := filepath.Glob(filepath.Join(corpusDir, "*.html"))
+	if err != nil {
+		t.Fatalf("Failed to list corpus: %v", err)
+	}
+	if len(feeds) == 0 {
+		t.Fatal("Corpus is empty")
+	}
+	return feeds
+}
+
+// corpusFetchedAt returns the fetch time encoded in the name of a feed
+func corpusFetchedAt(t testing.TB, feed string) time.Time {
+	day, err := time.Parse("2006-01-02", strings.TrimSuffix(filepath.Base(feed), ".html"))
+	if err != nil {
+		t.Fatalf("Corpus feed %s is not named after its fetch date: %v", feed, err)
+	}
+	// The schedule is updated daily circa 06.00 UTC
+	return day.Add(6 * time.Hour)
+}
+
+// TestParseCorpus compares every field of the races parsed from each feed of
+// the corpus with its golden file. Run with -update to rewrite the goldens
+// after an intended change, then review the diff.
+func TestParseCorpus(t *testing.T) {
+	for _, feed := range corpusFeeds(t) {
+		name := strings.TrimSuffix(filepath.Base(feed), ".html")
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(feed)
+			if err != nil {
+				t.Fatalf("Failed to read feed: %v", err)
+			}
+
+			races, _, err := parseTizRaces(string(content), corpusFetchedAt(t, feed))
+			if err != nil {
+				t.Fatalf("parseTizRaces returned error: %v", err)
+			}
+			for _, race := range races {
+				// Notes of the schedule illustrated with an image are not races
+				if isZoneName(race.Name) {
+					t.Errorf("Race named after a time zone: %+v", race)
+				}
+			}
+
+			got, err := json.MarshalIndent(races, "", "  ")
+			if err != nil {
+				t.Fatalf("Failed to encode races: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join(corpusDir, name+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("Failed to write golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Races differ from %s (run with -update to accept):\n%s", golden, firstDifference(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// firstDifference describes the first line where two texts differ
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package request
+
+import (
+	"bytes"
+	"cpe/calendar/types"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// diffIgnoredFields are the fields of a race left out of diffs: the markup
+// it was parsed from and the source it came from
+var diffIgnoredFields = map[string]bool{"raw_html": true, "source": true}
+
+// RaceDiff lists what changed between two schedules
+type RaceDiff struct {
+	Added    []types.TizRace `json:"added"`
+	Removed  []types.TizRace `json:"removed"`
+	Modified []RaceChange    `json:"modified"`
+}
+
+// RaceChange is a race present in both schedules whose fields differ
+type RaceChange struct {
+	Name    string        `json:"name"`
+	Stage   string        `json:"stage,omitempty"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange is a field of a race with its old and new JSON values
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// Empty tells whether both schedules hold the same races
+func (d RaceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffRaces compares two schedules. Races are matched by name and stage, so
+// a race moved to another day is modified, not removed and added again.
+func DiffRaces(old, new []types.TizRace) RaceDiff {
+	var diff RaceDiff
+
+	oldByKey := make(map[string]types.TizRace, len(old))
+	oldKeys := raceDiffKeys(old)
+	for i, race := range old {
+		oldByKey[oldKeys[i]] = race
+	}
+
+	seen := make(map[string]bool, len(new))
+	for i, key := range raceDiffKeys(new) {
+		seen[key] = true
+		previous, ok := oldByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, new[i])
+			continue
+		}
+		if changes := diffFields(previous, new[i]); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, RaceChange{Name: new[i].Name, Stage: new[i].Stage, Changes: changes})
+		}
+	}
+
+	for i, key := range oldKeys {
+		if !seen[key] {
+			diff.Removed = append(diff.Removed, old[i])
+		}
+	}
+
+	return diff
+}
+
+// raceDiffKeys identifies the races of a schedule across fetches. A race
+// listed several times with the same name and stage is told apart by its
+// start date, then by a count in the order of its fingerprint, so races
+// swapping places in the feed keep their keys.
+func raceDiffKeys(races []types.TizRace) []string {
+	groups := make(map[string][]int, len(races))
+	for i, race := range races {
+		key := strings.ToLower(strings.Join(strings.Fields(race.Name), " ")) + "|" + strings.ToLower(race.Stage)
+		groups[key] = append(groups[key], i)
+	}
+
+	keys := make([]string, len(races))
+	for key, group := range groups {
+		sort.SliceStable(group, func(a, b int) bool {
+			first, second := races[group[a]], races[group[b]]
+			if first.StartDate != second.StartDate {
+				return first.StartDate < second.StartDate
+			}
+			return raceFingerprint(first) < raceFingerprint(second)
+		})
+
+		used := make(map[string]int, len(group))
+		for n, i := range group {
+			raceKey := key
+			if n > 0 {
+				raceKey += "|" + races[i].StartDate
+			}
+			if count := used[raceKey]; count > 0 {
+				used[raceKey]++
+				raceKey += "|" + strconv.Itoa(count)
+			} else {
+				used[raceKey] = 1
+			}
+			keys[i] = raceKey
+		}
+	}
+	return keys
+}
+
+// diffFields compares the JSON encoding of every field of two races, which
+// ignores what the encoding drops (e.g. the location pointer of a time)
+func diffFields(old, new types.TizRace) []FieldChange {
+	var changes []FieldChange
+
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+	raceType := oldValue.Type()
+	for i := 0; i < raceType.NumField(); i++ {
+		field := strings.Split(raceType.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" || diffIgnoredFields[field] {
+			continue
+		}
+
+		before, errOld := json.Marshal(oldValue.Field(i).Interface())
+		after, errNew := json.Marshal(newValue.Field(i).Interface())
+		if errOld != nil || errNew != nil || bytes.Equal(before, after) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Old: before, New: after})
+	}
+
+	return changes
+}
@@ -0,0 +1,75 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"testing"
+	"time"
+)
+
+func TestDiffRaces(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot := func(clock string, hour int) []types.TizTimeSlot {
+		return []types.TizTimeSlot{{Time: clock, Start: time.Date(2026, 2, 7, hour, 0, 0, 0, paris), Location: "Europe/Paris"}}
+	}
+
+	old := []types.TizRace{
+		{Name: "Tour of Oman", Stage: "stage 1", StartDate: "2026-02-07", Times: slot("09:00:00 UTC", 10), RawHTML: "<li>old</li>"},
+		{Name: "Muscat Classic", StartDate: "2026-02-06"},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Country: "BE"},
+	}
+	new := []types.TizRace{
+		{Name: "Tour of Oman", Stage: "stage 1", StartDate: "2026-02-07", Times: slot("10:00:00 UTC", 11), RawHTML: "<li>new</li>"},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Country: "BE"},
+		{Name: "Kuurne-Brussel-Kuurne", StartDate: "2026-03-01"},
+	}
+
+	diff := DiffRaces(old, new)
+
+	if len(diff.Added) != 1 || diff.Added[0].Name != "Kuurne-Brussel-Kuurne" {
+		t.Errorf("Expected Kuurne-Brussel-Kuurne to be added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Muscat Classic" {
+		t.Errorf("Expected Muscat Classic to be removed, got %+v", diff.Removed)
+	}
+	if len(diff.Modified) != 1 {
+		t.Fatalf("Expected one modified race, got %+v", diff.Modified)
+	}
+	changes := diff.Modified[0].Changes
+	if len(changes) != 1 || changes[0].Field != "times" {
+		t.Fatalf("Expected only the times of Tour of Oman to change, got %+v", changes)
+	}
+
+	if same := DiffRaces(new, []types.TizRace{new[0], new[1], new[2]}); !same.Empty() {
+		t.Errorf("Expected no changes between identical schedules, got %+v", same)
+	}
+}
+
+func TestDiffRacesMovedDate(t *testing.T) {
+	old := []types.TizRace{{Name: "Strade Bianche", StartDate: "2026-03-07", EndDate: "2026-03-07"}}
+	new := []types.TizRace{{Name: "Strade Bianche", StartDate: "2026-03-08", EndDate: "2026-03-08"}}
+
+	diff := DiffRaces(old, new)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("Expected a moved race to be modified, got %+v", diff)
+	}
+	if len(diff.Modified) != 1 || len(diff.Modified[0].Changes) != 2 {
+		t.Fatalf("Expected start and end dates to change, got %+v", diff.Modified)
+	}
+	if got := string(diff.Modified[0].Changes[0].New); got != `"2026-03-08"` {
+		t.Errorf("Expected new start date, got %s", got)
+	}
+}
+
+func TestDiffRacesSwappedDuplicates(t *testing.T) {
+	women := types.TizRace{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Categories: []string{"WE"}, Country: "BE"}
+	men := types.TizRace{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Categories: []string{"ME"}, Country: "BE"}
+	later := types.TizRace{Name: "Omloop Het Nieuwsblad", StartDate: "2026-03-01", Categories: []string{"MU"}, Country: "BE"}
+
+	diff := DiffRaces([]types.TizRace{women, men, later}, []types.TizRace{later, men, women})
+	if !diff.Empty() {
+		t.Errorf("Expected no changes when same-key races swap places, got %+v", diff)
+	}
+}
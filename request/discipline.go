@@ -0,0 +1,68 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"regexp"
+	"strings"
+)
+
+var (
+	// disciplineKeywords are checked in order on the name, stage and notes of a
+	// race: BMX supercross and MTB eliminators must not read as cyclocross
+	disciplineKeywords = []struct {
+		discipline types.Discipline
+		pattern    *regexp.Regexp
+	}{
+		{types.DisciplineBMX, regexp.MustCompile(`(?i)\bbmx\b`)},
+		{types.DisciplineMTB, regexp.MustCompile(`(?i)\b(?:mtb|mountain ?bike|xc[cmo]|downhill|enduro)\b`)},
+		{types.DisciplineTrack, regexp.MustCompile(`(?i)\b(?:track|velodrom[eo]?|omnium|keirin|madison|six days|6 days)\b`)},
+		{types.DisciplineGravel, regexp.MustCompile(`(?i)\bgravel\b`)},
+		// "Waaslandcross", "Exact Cross", "Final CX", but not "Race Across America"
+		{types.DisciplineCyclocross, regexp.MustCompile(`(?i)\b(?:cyclo-?cross|cross|cx|veldrit|[a-z]*[^a\s]cross)\b`)},
+	}
+
+	// knownDisciplines maps races and series whose name does not tell their
+	// discipline, matched on the lower-cased race name
+	knownDisciplines = map[string]types.Discipline{
+		"superprestige":       types.DisciplineCyclocross,
+		"x2o badkamers":       types.DisciplineCyclocross,
+		"gp sven nys":         types.DisciplineCyclocross,
+		"sluitingsprijs":      types.DisciplineCyclocross,
+		"koppenberg":          types.DisciplineCyclocross,
+		"cape epic":           types.DisciplineMTB,
+		"mediterranean epic":  types.DisciplineMTB,
+		"andalucia bike race": types.DisciplineMTB,
+		"unbound":             types.DisciplineGravel,
+		"the traka":           types.DisciplineGravel,
+	}
+)
+
+// classifyDiscipline tells the discipline of a race from its categories, the
+// keywords of its name and the races known to the calendar. Races matching
+// none of them are road races.
+func classifyDiscipline(race types.TizRace) types.Discipline {
+	for _, category := range race.Categories {
+		switch strings.ToLower(category) {
+		case "track":
+			return types.DisciplineTrack
+		case "mtb":
+			return types.DisciplineMTB
+		}
+	}
+
+	text := strings.Join([]string{race.Name, race.Stage, race.Notes}, " ")
+	for _, keyword := range disciplineKeywords {
+		if keyword.pattern.MatchString(text) {
+			return keyword.discipline
+		}
+	}
+
+	name := strings.ToLower(race.Name)
+	for known, discipline := range knownDisciplines {
+		if strings.Contains(name, known) {
+			return discipline
+		}
+	}
+
+	return types.DisciplineRoad
+}
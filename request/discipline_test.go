@@ -0,0 +1,37 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"testing"
+)
+
+func TestClassifyDiscipline(t *testing.T) {
+	tests := []struct {
+		race types.TizRace
+		want types.Discipline
+	}{
+		{types.TizRace{Name: "Exact Cross Maldegem - Parkcross 2026", Categories: []string{"WE", "ME"}}, types.DisciplineCyclocross},
+		{types.TizRace{Name: "X2O Badkamers Trofee Lille - Krawatencross 2026"}, types.DisciplineCyclocross},
+		{types.TizRace{Name: "GP Sven Nys", Categories: []string{"WE", "ME", "JR"}}, types.DisciplineCyclocross},
+		{types.TizRace{Name: "Internationale Sluitingsprijs Oostmalle 2026"}, types.DisciplineCyclocross},
+		{types.TizRace{Name: "Dutch National Cyclo-cross Championships", Categories: []string{"NC"}}, types.DisciplineCyclocross},
+		{types.TizRace{Name: "Državno Prvenstvo Velodrom 2026", Categories: []string{"track"}}, types.DisciplineTrack},
+		{types.TizRace{Name: "UCI Track Nations Cup Konya", Stage: "day 2 (of 3)"}, types.DisciplineTrack},
+		{types.TizRace{Name: "Six Days of Ghent"}, types.DisciplineTrack},
+		{types.TizRace{Name: "Scott Mediterranean Epic MTB", Stage: "stage 1 (of 4)"}, types.DisciplineMTB},
+		{types.TizRace{Name: "Absa Cape Epic"}, types.DisciplineMTB},
+		{types.TizRace{Name: "UCI XCO World Cup Araxá"}, types.DisciplineMTB},
+		{types.TizRace{Name: "UCI BMX Supercross World Cup"}, types.DisciplineBMX},
+		{types.TizRace{Name: "UCI Gravel World Championships"}, types.DisciplineGravel},
+		{types.TizRace{Name: "Unbound 200"}, types.DisciplineGravel},
+		{types.TizRace{Name: "Race Across America"}, types.DisciplineRoad},
+		{types.TizRace{Name: "Tour of Oman", Categories: []string{"ME"}}, types.DisciplineRoad},
+		{types.TizRace{Name: "Slovenian Championships", Notes: "Slovenian track NCs"}, types.DisciplineTrack},
+	}
+
+	for _, tt := range tests {
+		if got := classifyDiscipline(tt.race); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.race.Name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/types"
+	"sync"
+)
+
+// refreshCall is an upstream refresh shared by every caller that asked for
+// it while it was in flight
+type refreshCall struct {
+	done  chan struct{}
+	races []types.TizRace
+	err   error
+}
+
+var (
+	inflight struct {
+		sync.Mutex
+		call *refreshCall
+	}
+)
+
+// startRefresh returns the in-flight refresh, starting one if none is running.
+// The fetch runs detached from any caller so an impatient client cannot
+// abort it for the others.
+func startRefresh() *refreshCall {
+	inflight.Lock()
+	defer inflight.Unlock()
+
+	if inflight.call != nil {
+		return inflight.call
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	inflight.call = call
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		call.races, call.err = updateTizCache(ctx)
+
+		inflight.Lock()
+		inflight.call = nil
+		inflight.Unlock()
+		close(call.done)
+	}()
+
+	return call
+}
+
+// refreshTizRaces refreshes the cache and waits for the result. Concurrent
+// callers share a single upstream fetch and its result or error.
+func refreshTizRaces(ctx context.Context) ([]types.TizRace, error) {
+	call := startRefresh()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	races := make([]types.TizRace, len(call.races))
+	copy(races, call.races)
+	return races, nil
+}
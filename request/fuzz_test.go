@@ -0,0 +1,135 @@
+package request
+
+import (
+	"cpe/calendar/dates"
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/net/html"
+)
+
+var fuzzRef = time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+
+// quietLogs drops the logs of the parser for the duration of a fuzz target,
+// writing them would dominate the time of each run
+func quietLogs(f *testing.F) {
+	previous := logger.Log
+	logger.Log = zerolog.Nop()
+	f.Cleanup(func() { logger.Log = previous })
+}
+
+// corpusEntries returns the inner HTML of every <li> of the corpus feeds
+func corpusEntries(f *testing.F) []string {
+	var entries []string
+	for _, feed := range corpusFeeds(f) {
+		content, err := os.ReadFile(feed)
+		if err != nil {
+			f.Fatalf("Failed to read feed: %v", err)
+		}
+		for _, entry := range strings.Split(string(content), "<li>") {
+			entries = append(entries, strings.TrimSuffix(strings.TrimSpace(entry), "</li>"))
+		}
+	}
+	return entries
+}
+
+// FuzzParseTizRaces checks that no page makes the parser panic or return
+// races breaking the invariants of checkRace
+func FuzzParseTizRaces(f *testing.F) {
+	quietLogs(f)
+	for _, feed := range corpusFeeds(f) {
+		content, err := os.ReadFile(feed)
+		if err != nil {
+			f.Fatalf("Failed to read feed: %v", err)
+		}
+		f.Add(string(content))
+	}
+
+	f.Fuzz(func(t *testing.T, feed string) {
+		races, report, err := parseTizRaces(feed, fuzzRef)
+		if err != nil {
+			return
+		}
+		if report.RacesParsed != len(races) {
+			t.Errorf("Report counts %d races, parsed %d", report.RacesParsed, len(races))
+		}
+		for _, race := range races {
+			if strings.TrimSpace(race.Name) == "" || isZoneName(race.Name) {
+				t.Errorf("Race without a name: %+v", race)
+			}
+			checkRace(t, race)
+		}
+	})
+}
+
+// FuzzParseRaceFromLi checks the invariants of checkRace on single entries,
+// in each of the sections parseTizRaces can pass
+func FuzzParseRaceFromLi(f *testing.F) {
+	quietLogs(f)
+	sections := []string{"2026-02-04", "2026-02-05", "UPCOMING", "TOMORROW", ""}
+	for i, entry := range corpusEntries(f) {
+		f.Add(entry, uint8(i))
+	}
+
+	f.Fuzz(func(t *testing.T, entry string, sectionIndex uint8) {
+		section := sections[int(sectionIndex)%len(sections)]
+		doc, err := html.Parse(strings.NewReader("<ul><li>" + entry + "</li></ul>"))
+		if err != nil {
+			return
+		}
+		li := findNode(doc, isLiElement)
+		if li == nil {
+			return
+		}
+
+		race, err := parseRaceFromLi(li, section, fuzzRef)
+		if err != nil {
+			return
+		}
+		checkRace(t, race)
+	})
+}
+
+// checkRace verifies the invariants every parsed race must hold
+func checkRace(t *testing.T, race types.TizRace) {
+	t.Helper()
+
+	switch race.DatePrecision {
+	case types.DatePrecisionUnknown:
+		if race.StartDate != "" || race.EndDate != "" {
+			t.Errorf("Race with an unknown date has dates %s..%s", race.StartDate, race.EndDate)
+		}
+	case types.DatePrecisionDay, types.DatePrecisionMonth:
+		start, err := dates.Parse(race.StartDate)
+		if err != nil {
+			t.Errorf("Invalid start date %q", race.StartDate)
+			return
+		}
+		end, err := dates.Parse(race.EndDate)
+		if err != nil {
+			t.Errorf("Invalid end date %q", race.EndDate)
+			return
+		}
+		if end.Before(start) {
+			t.Errorf("End date %s is before start date %s", race.EndDate, race.StartDate)
+		}
+	default:
+		t.Errorf("Unexpected date precision %q", race.DatePrecision)
+	}
+
+	for _, slot := range race.Times {
+		for _, date := range slot.Dates {
+			if _, err := dates.Parse(date); err != nil {
+				t.Errorf("Invalid slot date %q", date)
+			}
+		}
+		if !slot.Start.IsZero() && !strings.HasSuffix(slot.Time, " UTC") {
+			t.Errorf("Resolved slot time %q is not in UTC", slot.Time)
+		}
+	}
+}
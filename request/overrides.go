@@ -0,0 +1,509 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"cpe/calendar/countries"
+	"cpe/calendar/dates"
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	overridesVersion = 1
+	// overrideSource is the source name of the races injected by the overrides file
+	overrideSource = "override"
+)
+
+var (
+	// overridesPath is the overrides file, empty disables overrides
+	overridesPath = ""
+	// overridesPollInterval is how often the overrides file is checked for changes
+	overridesPollInterval = 2 * time.Second
+
+	overrides struct {
+		sync.RWMutex
+		file      overridesFile
+		modTime   time.Time // Modification time of the file when it was loaded
+		size      int64
+		loadedAt  time.Time
+		err       error             // Error of the last load, nil if it succeeded
+		applied   []AppliedOverride // Of the last schedule served
+		unmatched []string          // Overrides that matched no race of the last schedule served
+	}
+)
+
+// overridesFile is the declarative overrides file, in YAML or JSON
+type overridesFile struct {
+	Version   int            `json:"version" yaml:"version"`
+	Overrides []raceOverride `json:"overrides" yaml:"overrides"` // Patches and hides of parsed races
+	Races     []racePatch    `json:"races" yaml:"races"`         // Races added to the schedule
+}
+
+// raceOverride patches or hides the races matching Match
+type raceOverride struct {
+	Match  raceMatch  `json:"match" yaml:"match"`
+	Hide   bool       `json:"hide,omitempty" yaml:"hide"`
+	Set    *racePatch `json:"set,omitempty" yaml:"set"`
+	Reason string     `json:"reason,omitempty" yaml:"reason"`
+}
+
+// raceMatch identifies races by their UID, or by name, optionally narrowed
+// by stage and start date. Names and stages are compared ignoring case,
+// accents and punctuation.
+type raceMatch struct {
+	UID       string `json:"uid,omitempty" yaml:"uid"`
+	Name      string `json:"name,omitempty" yaml:"name"`
+	Stage     string `json:"stage,omitempty" yaml:"stage"`
+	StartDate string `json:"start_date,omitempty" yaml:"start_date"`
+}
+
+// racePatch holds the fields to set on a race, nil fields are kept
+type racePatch struct {
+	Name           *string  `json:"name,omitempty" yaml:"name"`
+	Stage          *string  `json:"stage,omitempty" yaml:"stage"`
+	StartDate      *string  `json:"start_date,omitempty" yaml:"start_date"`
+	EndDate        *string  `json:"end_date,omitempty" yaml:"end_date"`
+	AllDay         *bool    `json:"all_day,omitempty" yaml:"all_day"`
+	Times          *string  `json:"times,omitempty" yaml:"times"` // As written in the feed: WE 12.40 UTC (60 mins) - ME 14.00 UTC
+	Duration       *string  `json:"duration,omitempty" yaml:"duration"`
+	Categories     []string `json:"categories,omitempty" yaml:"categories"`
+	Country        *string  `json:"country,omitempty" yaml:"country"`
+	Notes          *string  `json:"notes,omitempty" yaml:"notes"`
+	StreamType     *string  `json:"stream_type,omitempty" yaml:"stream_type"`
+	Discipline     *string  `json:"discipline,omitempty" yaml:"discipline"`
+	Classification *string  `json:"classification,omitempty" yaml:"classification"`
+	Status         *string  `json:"status,omitempty" yaml:"status"` // confirmed, tentative or cancelled
+}
+
+// AppliedOverride is an override applied to a race of the schedule
+type AppliedOverride struct {
+	Match  string `json:"match,omitempty"` // Match of the override, absent for added races
+	Action string `json:"action"`          // patched, hidden or added
+	Race   string `json:"race"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OverridesReport describes the overrides file and what it did to the last
+// schedule served
+type OverridesReport struct {
+	Path      string            `json:"path"`
+	LoadedAt  time.Time         `json:"loaded_at"`
+	Error     string            `json:"error,omitempty"` // Of the last load, the previous overrides are kept
+	Overrides int               `json:"overrides"`
+	Races     int               `json:"races"`
+	Applied   []AppliedOverride `json:"applied"`
+	Unmatched []string          `json:"unmatched"`
+}
+
+// LoadOverrides reads and validates the overrides file. On failure the
+// previous overrides are kept and the error is recorded.
+func LoadOverrides() error {
+	if overridesPath == "" {
+		overrides.Lock()
+		overrides.file = overridesFile{}
+		overrides.err = nil
+		overrides.Unlock()
+		sourcesRefreshed()
+		return nil
+	}
+
+	info, err := os.Stat(overridesPath)
+	if err == nil {
+		err = loadOverrides(info)
+	}
+	if err != nil {
+		overrides.Lock()
+		overrides.err = err
+		overrides.Unlock()
+	}
+	return err
+}
+
+// loadOverrides reads the overrides file into the active overrides
+func loadOverrides(info os.FileInfo) error {
+	content, err := os.ReadFile(overridesPath)
+	if err != nil {
+		return fmt.Errorf("failed to read overrides: %w", err)
+	}
+
+	file, err := parseOverrides(content, filepath.Ext(overridesPath))
+	if err != nil {
+		return fmt.Errorf("invalid overrides %s: %w", overridesPath, err)
+	}
+
+	overrides.Lock()
+	overrides.file = file
+	overrides.modTime = info.ModTime()
+	overrides.size = info.Size()
+	overrides.loadedAt = time.Now()
+	overrides.err = nil
+	overrides.Unlock()
+	sourcesRefreshed()
+
+	logger.Log.Info().Str("path", overridesPath).Int("overrides", len(file.Overrides)).Int("races", len(file.Races)).Msg("Loaded race overrides")
+	return nil
+}
+
+// WatchOverrides reloads the overrides file whenever it changes, until ctx
+// is cancelled
+func WatchOverrides(ctx context.Context) {
+	if overridesPath == "" {
+		return
+	}
+
+	ticker := time.NewTicker(overridesPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(overridesPath)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("path", overridesPath).Msg("Failed to check overrides")
+			continue
+		}
+
+		overrides.RLock()
+		changed := !info.ModTime().Equal(overrides.modTime) || info.Size() != overrides.size
+		overrides.RUnlock()
+		if !changed {
+			continue
+		}
+
+		logger.Log.Info().Str("path", overridesPath).Msg("Overrides changed, reloading")
+		if err := loadOverrides(info); err != nil {
+			logger.Log.Error().Err(err).Msg("Keeping previous overrides")
+			overrides.Lock()
+			overrides.err = err
+			// Do not report the same broken file on every tick
+			overrides.modTime, overrides.size = info.ModTime(), info.Size()
+			overrides.Unlock()
+			continue
+		}
+		prepareRaces(ctx)
+	}
+}
+
+// parseOverrides decodes an overrides file, YAML unless ext is .json, and
+// validates it
+func parseOverrides(content []byte, ext string) (overridesFile, error) {
+	var file overridesFile
+	if strings.EqualFold(ext, ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return file, fmt.Errorf("failed to decode: %w", err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return file, fmt.Errorf("failed to decode: %w", err)
+		}
+	}
+	if file.Version != overridesVersion {
+		return file, fmt.Errorf("unsupported version %d (expected %d)", file.Version, overridesVersion)
+	}
+
+	var errs []error
+	for i, override := range file.Overrides {
+		fail := func(err error) { errs = append(errs, fmt.Errorf("override %d: %w", i, err)) }
+
+		match := override.Match
+		if match.UID == "" && match.Name == "" {
+			fail(errors.New("match needs a uid or a name"))
+		}
+		if match.StartDate != "" {
+			if _, err := dates.Parse(match.StartDate); err != nil {
+				fail(fmt.Errorf("invalid match start_date: %w", err))
+			}
+		}
+		switch {
+		case override.Hide && override.Set != nil:
+			fail(errors.New("hide and set are exclusive"))
+		case !override.Hide && override.Set == nil:
+			fail(errors.New("nothing to do, expected hide or set"))
+		case override.Set != nil:
+			for _, err := range override.Set.validate() {
+				fail(err)
+			}
+		}
+	}
+	for i, race := range file.Races {
+		fail := func(err error) { errs = append(errs, fmt.Errorf("race %d: %w", i, err)) }
+
+		if race.Name == nil || strings.TrimSpace(*race.Name) == "" {
+			fail(errors.New("name is required"))
+		}
+		if race.StartDate == nil {
+			fail(errors.New("start_date is required"))
+		}
+		for _, err := range race.validate() {
+			fail(err)
+		}
+	}
+
+	return file, errors.Join(errs...)
+}
+
+// validate checks every field set by a patch
+func (p racePatch) validate() []error {
+	var errs []error
+
+	var start, end time.Time
+	if p.StartDate != nil {
+		var err error
+		if start, err = dates.Parse(*p.StartDate); err != nil {
+			errs = append(errs, fmt.Errorf("invalid start_date: %w", err))
+		}
+	}
+	if p.EndDate != nil {
+		var err error
+		if end, err = dates.Parse(*p.EndDate); err != nil {
+			errs = append(errs, fmt.Errorf("invalid end_date: %w", err))
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		errs = append(errs, fmt.Errorf("end_date %s is before start_date %s", *p.EndDate, *p.StartDate))
+	}
+	if p.Times != nil && len(extractTimes(*p.Times)) == 0 {
+		errs = append(errs, fmt.Errorf("no time found in times %q", *p.Times))
+	}
+	if p.Times != nil && p.AllDay != nil && *p.AllDay {
+		errs = append(errs, errors.New("times and all_day are exclusive"))
+	}
+	for _, category := range p.Categories {
+		if _, ok := types.TizCategoryMap[category]; !ok {
+			errs = append(errs, fmt.Errorf("unknown category %q", category))
+		}
+	}
+	if p.Country != nil {
+		if _, ok := countries.Lookup(*p.Country); !ok {
+			errs = append(errs, fmt.Errorf("unknown country %q", *p.Country))
+		}
+	}
+	if p.Discipline != nil {
+		if _, ok := types.ParseDiscipline(*p.Discipline); !ok {
+			errs = append(errs, fmt.Errorf("unknown discipline %q", *p.Discipline))
+		}
+	}
+	if p.Classification != nil {
+		if _, ok := types.ClassificationTier(*p.Classification); !ok {
+			errs = append(errs, fmt.Errorf("unknown UCI class %q", *p.Classification))
+		}
+	}
+	if p.Status != nil {
+		switch strings.ToUpper(*p.Status) {
+		case types.StatusConfirmed, types.StatusTentative, types.StatusCancelled:
+		default:
+			errs = append(errs, fmt.Errorf("unknown status %q (expected confirmed, tentative or cancelled)", *p.Status))
+		}
+	}
+
+	return errs
+}
+
+// applyOverrides patches, hides and adds the races of the overrides file,
+// recording what was done for the diagnostics
+func applyOverrides(races []types.TizRace) []types.TizRace {
+	overrides.RLock()
+	file := overrides.file
+	overrides.RUnlock()
+
+	if len(file.Overrides) == 0 && len(file.Races) == 0 {
+		return races
+	}
+
+	var applied []AppliedOverride
+	matched := make([]bool, len(file.Overrides))
+	kept := make([]types.TizRace, 0, len(races)+len(file.Races))
+	for _, race := range races {
+		hidden := false
+		for i, override := range file.Overrides {
+			if !override.Match.matches(race) {
+				continue
+			}
+			matched[i] = true
+			if override.Hide {
+				applied = append(applied, AppliedOverride{Match: override.Match.String(), Action: "hidden", Race: race.Name, Reason: override.Reason})
+				hidden = true
+				break
+			}
+			override.Set.apply(&race)
+			applied = append(applied, AppliedOverride{Match: override.Match.String(), Action: "patched", Race: race.Name, Reason: override.Reason})
+		}
+		if !hidden {
+			kept = append(kept, race)
+		}
+	}
+
+	for _, patch := range file.Races {
+		race := types.TizRace{Source: overrideSource, DatePrecision: types.DatePrecisionDay, AllDay: true}
+		patch.apply(&race)
+		if race.EndDate == "" {
+			race.EndDate = race.StartDate
+		}
+		kept = append(kept, race)
+		applied = append(applied, AppliedOverride{Action: "added", Race: race.Name})
+	}
+
+	var unmatched []string
+	for i, override := range file.Overrides {
+		if !matched[i] {
+			unmatched = append(unmatched, override.Match.String())
+		}
+	}
+	if len(unmatched) > 0 {
+		logger.Log.Warn().Strs("overrides", unmatched).Msg("Overrides matched no race")
+	}
+
+	overrides.Lock()
+	overrides.applied, overrides.unmatched = applied, unmatched
+	overrides.Unlock()
+
+	return kept
+}
+
+// matches tells whether race is identified by m. UIDs are compared to the
+// UID served, which must be assigned.
+func (m raceMatch) matches(race types.TizRace) bool {
+	if m.UID != "" && race.UID != m.UID {
+		return false
+	}
+	if m.Name != "" && slugify(m.Name) != slugify(race.Name) {
+		return false
+	}
+	if m.Stage != "" && slugify(m.Stage) != slugify(race.Stage) {
+		return false
+	}
+	return m.StartDate == "" || m.StartDate == race.StartDate
+}
+
+// String describes a match in logs and reports
+func (m raceMatch) String() string {
+	var parts []string
+	for _, part := range []string{m.UID, m.Name, m.Stage, m.StartDate} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// apply sets the fields of a patch on race. New times are parsed as the
+// feed writes them and resolved on the race dates, as are the times of the
+// feed when the start date or the duration changes.
+func (p racePatch) apply(race *types.TizRace) {
+	set := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	set(&race.Name, p.Name)
+	set(&race.Stage, p.Stage)
+	set(&race.EndDate, p.EndDate)
+	set(&race.Duration, p.Duration)
+	set(&race.Notes, p.Notes)
+	set(&race.StreamType, p.StreamType)
+	set(&race.Classification, p.Classification)
+
+	if p.StartDate != nil {
+		race.StartDate = *p.StartDate
+		race.DatePrecision = types.DatePrecisionDay
+		if race.EndDate < race.StartDate {
+			race.EndDate = race.StartDate
+		}
+	}
+	if p.Categories != nil {
+		race.Categories = p.Categories
+	}
+	if p.Country != nil {
+		country, _ := countries.Lookup(*p.Country)
+		race.Country, race.CountryName = country.Alpha2, country.Name
+	}
+	if p.Discipline != nil {
+		race.Discipline, _ = types.ParseDiscipline(*p.Discipline)
+	}
+	if p.AllDay != nil {
+		race.AllDay = *p.AllDay
+	}
+	if p.Status != nil {
+		race.Status, race.StatusNote = strings.ToUpper(*p.Status), ""
+		if race.Status == types.StatusCancelled {
+			race.StatusNote = "Cancelled"
+		}
+	}
+
+	switch {
+	case p.Times != nil:
+		race.Times = extractTimes(*p.Times)
+		race.AllDay = false
+	case (p.StartDate != nil || p.Duration != nil) && len(race.Times) > 0:
+		// Slots of the feed, pinned to the previous dates: back to the wall
+		// clock they were written in
+		times := make([]types.TizTimeSlot, len(race.Times))
+		for i, slot := range race.Times {
+			if !slot.Start.IsZero() {
+				slot.Time = slot.Start.Format("15:04")
+			}
+			slot.Dates, slot.Start = nil, time.Time{}
+			times[i] = slot
+		}
+		race.Times = times
+	default:
+		return
+	}
+
+	if p.Duration != nil {
+		for i := range race.Times {
+			race.Times[i].Duration = *p.Duration
+		}
+	}
+	if start, err := dates.Parse(race.StartDate); err == nil {
+		resolveSlotDates(race, start)
+	}
+	resolveSlotTimes(race)
+	if p.Duration == nil {
+		race.Duration = calculateDurationFromTimes(race.Times, race.StartDate)
+	}
+}
+
+// LatestOverridesReport describes the overrides file, and false when no
+// overrides file is configured
+func LatestOverridesReport() (OverridesReport, bool) {
+	if overridesPath == "" {
+		return OverridesReport{}, false
+	}
+
+	overrides.RLock()
+	defer overrides.RUnlock()
+
+	report := OverridesReport{
+		Path:      overridesPath,
+		LoadedAt:  overrides.loadedAt,
+		Overrides: len(overrides.file.Overrides),
+		Races:     len(overrides.file.Races),
+		Applied:   overrides.applied,
+		Unmatched: overrides.unmatched,
+	}
+	if overrides.err != nil {
+		report.Error = overrides.err.Error()
+	}
+	return report, true
+}
@@ -0,0 +1,286 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetOverrides points the overrides at path, empty for none
+func resetOverrides(t *testing.T, path string) {
+	t.Helper()
+
+	previousPath, previousInterval := overridesPath, overridesPollInterval
+	overridesPath, overridesPollInterval = path, 10*time.Millisecond
+	t.Cleanup(func() {
+		overridesPath, overridesPollInterval = previousPath, previousInterval
+		overrides.Lock()
+		overrides.file, overrides.err = overridesFile{}, nil
+		overrides.Unlock()
+	})
+
+	overrides.Lock()
+	overrides.file = overridesFile{}
+	overrides.modTime = time.Time{}
+	overrides.size = 0
+	overrides.err = nil
+	overrides.applied, overrides.unmatched = nil, nil
+	overrides.Unlock()
+	sourcesRefreshed()
+}
+
+// writeOverrides writes an overrides file into dir
+func writeOverrides(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testOverrides = `version: 1
+overrides:
+  - match: {name: "Tour Down Under", stage: "Stage 1"}
+    set:
+      name: Santos Tour Down Under
+      times: WE 01.30 UTC (90 mins)
+    reason: Wrong time in the feed
+  - match: {name: "Vuelta a San Juan"}
+    hide: true
+    reason: Cancelled
+  - match: {name: "Tour of Nowhere"}
+    hide: true
+races:
+  - name: Nationals Time Trial
+    start_date: "2026-01-10"
+    categories: [ME]
+    country: GBR
+    discipline: road
+`
+
+func TestApplyOverrides(t *testing.T) {
+	resetOverrides(t, writeOverrides(t, t.TempDir(), "overrides.yml", testOverrides))
+	if err := LoadOverrides(); err != nil {
+		t.Fatalf("LoadOverrides returned error: %v", err)
+	}
+
+	races := applyOverrides([]types.TizRace{
+		{Name: "Tour Down Under", Stage: "Stage 1", StartDate: "2026-01-20", EndDate: "2026-01-20", DatePrecision: types.DatePrecisionDay, AllDay: true},
+		{Name: "Vuelta a San Juan", StartDate: "2026-01-25", EndDate: "2026-01-30", DatePrecision: types.DatePrecisionDay},
+		{Name: "Tour Down Under", Stage: "Stage 2", StartDate: "2026-01-21", EndDate: "2026-01-21", DatePrecision: types.DatePrecisionDay},
+	})
+
+	if len(races) != 3 {
+		t.Fatalf("Expected 3 races, got %d: %+v", len(races), races)
+	}
+
+	patched := races[0]
+	if patched.Name != "Santos Tour Down Under" || patched.AllDay || len(patched.Times) != 1 {
+		t.Fatalf("Expected the first stage to be renamed and timed, got %+v", patched)
+	}
+	if got := patched.Times[0].Start.UTC().Format(time.RFC3339); got != "2026-01-20T01:30:00Z" {
+		t.Errorf("Expected the patched time on the race day, got %s", got)
+	}
+	if patched.Duration != "90 mins" {
+		t.Errorf("Expected the duration of the patched times, got %q", patched.Duration)
+	}
+	if races[1].Name != "Tour Down Under" || races[1].Stage != "Stage 2" {
+		t.Errorf("Expected the second stage to be left alone, got %+v", races[1])
+	}
+
+	added := races[2]
+	if added.Source != overrideSource || added.EndDate != "2026-01-10" || !added.AllDay || added.Country != "GB" || added.CountryName == "" {
+		t.Errorf("Unexpected added race %+v", added)
+	}
+
+	report, ok := LatestOverridesReport()
+	if !ok {
+		t.Fatal("Expected an overrides report")
+	}
+	var actions []string
+	for _, applied := range report.Applied {
+		actions = append(actions, applied.Action+" "+applied.Race)
+	}
+	want := "patched Santos Tour Down Under, hidden Vuelta a San Juan, added Nationals Time Trial"
+	if got := strings.Join(actions, ", "); got != want {
+		t.Errorf("Expected applied %q, got %q", want, got)
+	}
+	if len(report.Unmatched) != 1 || report.Unmatched[0] != "Tour of Nowhere" {
+		t.Errorf("Expected the unmatched override to be reported, got %v", report.Unmatched)
+	}
+}
+
+func TestPatchStartDateResolvesTimes(t *testing.T) {
+	race := types.TizRace{Name: "Tour des Alpes-Maritimes", Country: "FR", StartDate: "2026-03-27", EndDate: "2026-03-28", DatePrecision: types.DatePrecisionDay}
+	race.Times = extractTimes("13.00 CET (120 mins), Sat 14.00 CET")
+	resolveSlotDates(&race, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+	resolveSlotTimes(&race)
+
+	// Moved after the change to summer time, the slots keep their wall clock
+	startDate, duration := "2026-04-03", "90 mins"
+	patched := race
+	racePatch{StartDate: &startDate}.apply(&patched)
+
+	if got := patched.Times[0].Start.UTC().Format(time.RFC3339); got != "2026-04-03T11:00:00Z" {
+		t.Errorf("Expected the first slot on the new start date, got %s", got)
+	}
+	if got := patched.Times[1].Dates; len(got) != 1 || got[0] != "2026-04-04" {
+		t.Errorf("Expected the Saturday slot on the new Saturday, got %v", got)
+	}
+	if got := race.Times[0].Start.UTC().Format(time.RFC3339); got != "2026-03-27T12:00:00Z" {
+		t.Errorf("Expected the original race to be left alone, got %s", got)
+	}
+	if patched.Duration != "120 mins" {
+		t.Errorf("Expected the duration of the first slot, got %q", patched.Duration)
+	}
+
+	racePatch{Duration: &duration}.apply(&patched)
+	if patched.Duration != duration || patched.Times[0].Duration != duration || patched.Times[0].Time != "11:00:00 UTC" {
+		t.Errorf("Expected the patched duration on the slots, got %q %+v", patched.Duration, patched.Times[0])
+	}
+}
+
+func TestOverridesMatchUID(t *testing.T) {
+	race := types.TizRace{Name: "Omloop Nieuwsblad", StartDate: "2026-02-28", EndDate: "2026-02-28", UID: "omloop-nieuwsblad-20260228-road-be@" + uidDomain}
+
+	if !(raceMatch{UID: race.UID}).matches(race) {
+		t.Error("Expected the race to match its UID")
+	}
+	if (raceMatch{UID: race.UID, StartDate: "2026-03-01"}).matches(race) {
+		t.Error("Expected the start date to narrow the match")
+	}
+	if !(raceMatch{Name: "omloop-nieuwsblad"}).matches(race) {
+		t.Error("Expected names to be compared ignoring case and punctuation")
+	}
+}
+
+func TestOverridesMatchServedUID(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+	resetOverrides(t, "")
+	day := time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+
+	// Served with a typo first, the fixed name keeps the UID through an alias
+	typo := enrichRaces([]types.TizRace{{Name: "Omloop Nieuwsblaad", StartDate: "2026-02-28", EndDate: "2026-02-28", Discipline: types.DisciplineRoad, Country: "BE"}}, day)
+	renamed := typo[0].UID
+	duplicate := "cyclo-cross-20260207-cyclocross-2@" + uidDomain
+
+	resetOverrides(t, writeOverrides(t, t.TempDir(), "overrides.yml", "version: 1\noverrides:\n"+
+		"  - match: {uid: "+renamed+"}\n    set: {notes: Renamed}\n"+
+		"  - match: {uid: "+duplicate+"}\n    hide: true\n"))
+	if err := LoadOverrides(); err != nil {
+		t.Fatalf("LoadOverrides returned error: %v", err)
+	}
+
+	served := enrichRaces([]types.TizRace{
+		{Name: "Omloop Nieuwsblad", StartDate: "2026-02-28", EndDate: "2026-02-28", Discipline: types.DisciplineRoad, Country: "BE"},
+		{Name: "Cyclo-cross", StartDate: "2026-02-07", EndDate: "2026-02-07", Discipline: types.DisciplineCyclocross, Notes: "First"},
+		{Name: "Cyclo-cross", StartDate: "2026-02-07", EndDate: "2026-02-07", Discipline: types.DisciplineCyclocross, Notes: "Second"},
+	}, day.Add(time.Hour))
+
+	if len(served) != 2 {
+		t.Fatalf("Expected the second cyclo-cross to be hidden, got %+v", served)
+	}
+	if served[0].UID != renamed || served[0].Notes != "Renamed" {
+		t.Errorf("Expected the renamed race to be patched under UID %s, got %s %q", renamed, served[0].UID, served[0].Notes)
+	}
+	if served[1].Notes != "First" {
+		t.Errorf("Expected the first cyclo-cross to be kept, got %+v", served[1])
+	}
+	if report, _ := LatestOverridesReport(); len(report.Unmatched) != 0 {
+		t.Errorf("Expected every override to match, got %v", report.Unmatched)
+	}
+}
+
+func TestParseOverridesRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		errors  []string
+	}{
+		{"version", ".yml", "version: 2\n", []string{"unsupported version 2"}},
+		{"unknown field", ".yml", "version: 1\noverrides:\n  - match: {name: X}\n    delete: true\n", []string{"field delete not found"}},
+		{"unknown json field", ".json", `{"version": 1, "races": [{"name": "X", "start_date": "2026-01-01", "colour": "red"}]}`, []string{`unknown field "colour"`}},
+		{"empty match", ".yml", "version: 1\noverrides:\n  - match: {}\n    hide: true\n", []string{"match needs a uid or a name"}},
+		{"no action", ".yml", "version: 1\noverrides:\n  - match: {name: X}\n", []string{"expected hide or set"}},
+		{"hide and set", ".json", `{"version": 1, "overrides": [{"match": {"name": "X"}, "hide": true, "set": {"notes": "n"}}]}`, []string{"hide and set are exclusive"}},
+		{
+			"invalid fields", ".yml",
+			"version: 1\noverrides:\n  - match: {name: X, start_date: 2026-02-30}\n    set: {end_date: 2026-01-01, start_date: 2026-01-05, times: soon, categories: [XX], country: ZZ, discipline: skating, classification: 3.HC, status: maybe}\n",
+			[]string{"invalid match start_date", "end_date 2026-01-01 is before start_date 2026-01-05", `no time found in times "soon"`, `unknown category "XX"`, `unknown country "ZZ"`, `unknown discipline "skating"`, `unknown UCI class "3.HC"`, `unknown status "maybe"`},
+		},
+		{"added race", ".yml", "version: 1\nraces:\n  - notes: Missing\n", []string{"race 0: name is required", "race 0: start_date is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOverrides([]byte(tt.content), tt.ext)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected %q in error %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchOverridesReloads(t *testing.T) {
+	dir := t.TempDir()
+	path := writeOverrides(t, dir, "overrides.json", `{"version": 1, "overrides": [{"match": {"name": "Tour Down Under"}, "hide": true}]}`)
+	resetOverrides(t, path)
+	if err := LoadOverrides(); err != nil {
+		t.Fatalf("LoadOverrides returned error: %v", err)
+	}
+
+	race := types.TizRace{Name: "Tour Down Under", StartDate: "2026-01-20", EndDate: "2026-01-20"}
+	if races := applyOverrides([]types.TizRace{race}); len(races) != 0 {
+		t.Fatal("Expected the race to be hidden")
+	}
+
+	// Stop the watcher before the cleanups of the test restore its settings
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WatchOverrides(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// A broken file keeps the previous overrides and reports the error
+	writeOverrides(t, dir, "overrides.json", `{"version": 1, "overrides": [{"match": {"name": "Tour Down Under"}}]}`)
+	deadline := time.Now().Add(2 * time.Second)
+	for report, _ := LatestOverridesReport(); report.Error == ""; report, _ = LatestOverridesReport() {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch did not report the broken overrides")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if races := applyOverrides([]types.TizRace{race}); len(races) != 0 {
+		t.Error("Expected the previous overrides to be kept")
+	}
+
+	writeOverrides(t, dir, "overrides.json", `{"version": 1, "overrides": [{"match": {"name": "Tour Down Under"}, "set": {"notes": "Fixed"}}]}`)
+	deadline = time.Now().Add(2 * time.Second)
+	for races := applyOverrides([]types.TizRace{race}); len(races) == 0 || races[0].Notes != "Fixed"; races = applyOverrides([]types.TizRace{race}) {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch did not pick up the fixed overrides")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if report, _ := LatestOverridesReport(); report.Error != "" {
+		t.Errorf("Expected the error to be cleared, got %q", report.Error)
+	}
+}
@@ -0,0 +1,190 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// replayPath is the captured feed, or directory of captured feeds, read
+	// by the file source
+	replayPath = ""
+	// replayPollInterval is how often the file source checks the feed for changes
+	replayPollInterval = 2 * time.Second
+
+	replay struct {
+		sync.RWMutex
+		races   []types.TizRace
+		file    string    // Feed the races were parsed from
+		modTime time.Time // Modification time of file when it was parsed
+		size    int64
+		err     error // Error of the last load, nil if it succeeded
+	}
+)
+
+// FileSource is the RaceSource replaying a captured Tiz schedule from disk,
+// so the service runs without network access
+type FileSource struct{}
+
+// Name returns the registry name of the file source
+func (FileSource) Name() string {
+	return "file"
+}
+
+// Fetch returns the races of the replayed feed, parsing it on first use
+func (FileSource) Fetch(ctx context.Context) ([]types.TizRace, error) {
+	replay.RLock()
+	loaded := replay.file != ""
+	replay.RUnlock()
+
+	if !loaded {
+		if err := reloadReplay(); err != nil {
+			return nil, err
+		}
+	}
+
+	replay.RLock()
+	defer replay.RUnlock()
+	races := make([]types.TizRace, len(replay.races))
+	copy(races, replay.races)
+	return races, nil
+}
+
+// Warning reports when the replayed feed changed but could not be parsed
+func (FileSource) Warning() string {
+	replay.RLock()
+	defer replay.RUnlock()
+	if replay.err == nil || replay.file == "" {
+		return ""
+	}
+	return fmt.Sprintf("replay feed unreadable, serving %s", filepath.Base(replay.file))
+}
+
+// Watch re-parses the replayed feed whenever it changes, until ctx is cancelled
+func (FileSource) Watch(ctx context.Context) {
+	ticker := time.NewTicker(replayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		file, info, err := replayFile(replayPath)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("path", replayPath).Msg("Failed to check replay feed")
+			continue
+		}
+
+		replay.RLock()
+		changed := file != replay.file || !info.ModTime().Equal(replay.modTime) || info.Size() != replay.size
+		replay.RUnlock()
+		if !changed {
+			continue
+		}
+
+		logger.Log.Info().Str("file", file).Msg("Replay feed changed, re-parsing")
+		if err := reloadReplay(); err != nil {
+			logger.Log.Warn().Err(err).Msg("Keeping previous replay races")
+			continue
+		}
+		prepareRaces(ctx)
+	}
+}
+
+// reloadReplay parses the replayed feed. On failure the previous races are
+// kept and the error is recorded.
+func reloadReplay() error {
+	file, info, err := replayFile(replayPath)
+	if err == nil {
+		err = loadReplay(file, info)
+	}
+	if err != nil {
+		replay.Lock()
+		replay.err = err
+		replay.Unlock()
+	}
+	return err
+}
+
+// loadReplay parses a captured feed into the replay races
+func loadReplay(file string, info os.FileInfo) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read replay feed: %w", err)
+	}
+
+	races, report, err := parseTizRaces(string(content), replayFetchedAt(file, info.ModTime()))
+	if err != nil {
+		return fmt.Errorf("failed to parse replay feed %s: %w", file, err)
+	}
+	publishParseReport(report)
+	if len(races) == 0 {
+		return fmt.Errorf("replay feed %s: %w", file, errNoRaces)
+	}
+
+	replay.Lock()
+	replay.races = races
+	replay.file = file
+	replay.modTime = info.ModTime()
+	replay.size = info.Size()
+	replay.err = nil
+	replay.Unlock()
+	sourcesRefreshed()
+
+	logger.Log.Info().Str("file", file).Int("raceCount", len(races)).Msg("Loaded replay feed")
+	return nil
+}
+
+// replayFile returns the feed to replay: path itself, or the last .html file
+// of the directory by name, captures being named after the day they were
+// fetched (2026-02-04.html)
+func replayFile(path string) (string, os.FileInfo, error) {
+	if path == "" {
+		return "", nil, errors.New("no replay path configured")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open replay path: %w", err)
+	}
+	if !info.IsDir() {
+		return path, info, nil
+	}
+
+	feeds, err := filepath.Glob(filepath.Join(path, "*.html"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list replay directory: %w", err)
+	}
+	if len(feeds) == 0 {
+		return "", nil, fmt.Errorf("no .html feed in replay directory %s", path)
+	}
+
+	// filepath.Glob sorts its matches
+	file := feeds[len(feeds)-1]
+	info, err = os.Stat(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open replay feed: %w", err)
+	}
+	return file, info, nil
+}
+
+// replayFetchedAt returns when a captured feed was fetched: the day in its
+// name at 06.00 UTC, when the schedule is updated, or else its modification time
+func replayFetchedAt(file string, modTime time.Time) time.Time {
+	day, err := time.Parse("2006-01-02", strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+	if err != nil {
+		return modTime
+	}
+	return day.Add(6 * time.Hour)
+}
@@ -0,0 +1,122 @@
+package request
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetReplay points the file source at path and forgets the loaded feed
+func resetReplay(t *testing.T, path string) {
+	t.Helper()
+
+	previousPath, previousInterval := replayPath, replayPollInterval
+	replayPath, replayPollInterval = path, 10*time.Millisecond
+	t.Cleanup(func() { replayPath, replayPollInterval = previousPath, previousInterval })
+
+	replay.Lock()
+	replay.races = nil
+	replay.file = ""
+	replay.modTime = time.Time{}
+	replay.size = 0
+	replay.err = nil
+	replay.Unlock()
+	sourcesRefreshed()
+}
+
+// copyCorpusFeed copies a feed of the corpus into dir
+func copyCorpusFeed(t *testing.T, name, dir string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(corpusDir, name))
+	if err != nil {
+		t.Fatalf("Failed to read feed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+		t.Fatalf("Failed to write feed: %v", err)
+	}
+}
+
+// hasRace tells whether a race with the given name prefix is in the file source
+func hasRace(t *testing.T, prefix string) bool {
+	t.Helper()
+
+	races, err := FileSource{}.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	for _, race := range races {
+		if strings.HasPrefix(race.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFileSourceReplaysLatestFeed(t *testing.T) {
+	dir := t.TempDir()
+	copyCorpusFeed(t, "2025-12-30.html", dir)
+	copyCorpusFeed(t, "2026-02-04.html", dir)
+	resetReplay(t, dir)
+
+	if !hasRace(t, "Volta Comunitat Valenciana") {
+		t.Error("Expected the races of the last feed of the directory")
+	}
+}
+
+func TestFileSourceWatchReparses(t *testing.T) {
+	dir := t.TempDir()
+	copyCorpusFeed(t, "2026-02-04.html", dir)
+	resetReplay(t, dir)
+
+	if hasRace(t, "Tour de France") {
+		t.Fatal("Tour de France is not in the February feed")
+	}
+
+	// Stop the watcher before the cleanups of the test restore its settings
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		FileSource{}.Watch(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	copyCorpusFeed(t, "2026-07-08.html", dir)
+	deadline := time.Now().Add(2 * time.Second)
+	for !hasRace(t, "Tour de France") {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch did not pick up the new feed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileSourceKeepsRacesOnBrokenFeed(t *testing.T) {
+	dir := t.TempDir()
+	copyCorpusFeed(t, "2026-02-04.html", dir)
+	resetReplay(t, dir)
+
+	if !hasRace(t, "Volta Comunitat Valenciana") {
+		t.Fatal("Expected the races of the feed")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "2026-02-05.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloadReplay(); err == nil {
+		t.Fatal("Expected an error for a feed without races")
+	}
+	if !hasRace(t, "Volta Comunitat Valenciana") {
+		t.Error("Expected the previous races to be kept")
+	}
+	if (FileSource{}).Warning() == "" {
+		t.Error("Expected a warning while serving the previous feed")
+	}
+}
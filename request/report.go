@@ -0,0 +1,142 @@
+package request
+
+import (
+	"cpe/calendar/metrics"
+	"cpe/calendar/types"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReportText bounds the raw text kept for an entry of a report
+const maxReportText = 300
+
+// Reasons for skipping an entry of the schedule
+const (
+	SkipNoFlag     = "no_flag"     // No flag image, so not a race
+	SkipEmptyName  = "empty_name"  // Looked like a race but no name was found
+	SkipNoDate     = "no_date"     // A race without a resolvable date (TBC)
+	SkipParseError = "parse_error" // parseRaceFromLi failed
+)
+
+// ParseReport describes how a schedule page was parsed
+type ParseReport struct {
+	ParsedAt      time.Time        `json:"parsed_at"`
+	EntriesSeen   int              `json:"entries_seen"`        // <li> elements in the page
+	Sections      int              `json:"sections"`            // TODAY, TOMORROW and UPCOMING headers
+	RacesParsed   int              `json:"races_parsed"`        // Entries turned into races
+	Skipped       []SkippedEntry   `json:"skipped"`             // Entries that are not races
+	FieldFailures []FieldFailure   `json:"field_failures"`      // Fields of parsed races that could not be read
+	Overrides     *OverridesReport `json:"overrides,omitempty"` // Overrides applied after parsing, when configured
+}
+
+// SkippedEntry is an entry of the schedule that did not produce a race
+type SkippedEntry struct {
+	Reason  string `json:"reason"`
+	Section string `json:"section,omitempty"`
+	Text    string `json:"text"`
+}
+
+// FieldFailure is a field of a race that could not be parsed
+type FieldFailure struct {
+	Race   string `json:"race"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Text   string `json:"text"`
+}
+
+var lastReport struct {
+	sync.RWMutex
+	report ParseReport
+	ok     bool
+}
+
+// skip records an entry that did not produce a race
+func (r *ParseReport) skip(reason, section, text string) {
+	r.Skipped = append(r.Skipped, SkippedEntry{Reason: reason, Section: section, Text: truncate(text, maxReportText)})
+}
+
+// checkFields records the fields of a parsed race that could not be read
+func (r *ParseReport) checkFields(race types.TizRace, text string) {
+	fail := func(field, reason string) {
+		r.FieldFailures = append(r.FieldFailures, FieldFailure{
+			Race:   race.Name,
+			Field:  field,
+			Reason: reason,
+			Text:   truncate(text, maxReportText),
+		})
+	}
+
+	if race.Country == "" {
+		fail("country", "flag not recognised")
+	}
+	if !race.AllDay && len(race.Times) == 0 {
+		fail("times", "no start time and times not marked TBA")
+	}
+	for _, slot := range race.Times {
+		if slot.Ambiguity != "" {
+			fail("times", slot.Ambiguity)
+		}
+	}
+}
+
+// SkippedByReason counts the skipped entries per reason
+func (r ParseReport) SkippedByReason() map[string]int {
+	counts := map[string]int{SkipNoFlag: 0, SkipEmptyName: 0, SkipNoDate: 0, SkipParseError: 0}
+	for _, entry := range r.Skipped {
+		counts[entry.Reason]++
+	}
+	return counts
+}
+
+// FailuresByField counts the field failures per field
+func (r ParseReport) FailuresByField() map[string]int {
+	counts := map[string]int{"country": 0, "times": 0}
+	for _, failure := range r.FieldFailures {
+		counts[failure.Field]++
+	}
+	return counts
+}
+
+// LatestParseReport returns the report of the last parsed Tiz schedule, and
+// false when nothing was parsed since startup
+func LatestParseReport() (ParseReport, bool) {
+	lastReport.RLock()
+	report, ok := lastReport.report, lastReport.ok
+	lastReport.RUnlock()
+
+	if overridesReport, configured := LatestOverridesReport(); configured {
+		report.Overrides = &overridesReport
+	}
+	return report, ok
+}
+
+// publishParseReport makes report the latest one and exports it as metrics
+func publishParseReport(report ParseReport) {
+	lastReport.Lock()
+	lastReport.report = report
+	lastReport.ok = true
+	lastReport.Unlock()
+
+	metrics.ParseTimestamp.Set(float64(report.ParsedAt.Unix()))
+	metrics.ParseEntriesSeen.Set(float64(report.EntriesSeen))
+	metrics.ParseRacesParsed.Set(float64(report.RacesParsed))
+	for reason, count := range report.SkippedByReason() {
+		metrics.ParseEntriesSkipped.WithLabelValues(reason).Set(float64(count))
+	}
+	for field, count := range report.FailuresByField() {
+		metrics.ParseFieldFailures.WithLabelValues(field).Set(float64(count))
+	}
+	metrics.UnresolvedRaces.Set(float64(report.SkippedByReason()[SkipNoDate]))
+}
+
+// truncate shortens text to at most n bytes, on a rune boundary
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n] + "…"
+}
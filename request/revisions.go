@@ -0,0 +1,153 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	revisionsVersion  = 2
+	revisionsFilename = "revisions.json"
+	// revisionRetention is how long the revision of a race no longer served is kept
+	revisionRetention = 400 * 24 * time.Hour
+)
+
+var revisions = struct {
+	sync.Mutex
+	entries map[string]revisionEntry
+}{entries: map[string]revisionEntry{}}
+
+// revisionEntry is the stored revision of a race
+type revisionEntry struct {
+	types.Revision
+	Fingerprint string    `json:"fingerprint"` // Hash of the fields calendar clients show
+	LastSeen    time.Time `json:"last_seen"`   // Day the race was last served
+}
+
+// revisionsFile is the on-disk representation of the revisions
+type revisionsFile struct {
+	Version   int                      `json:"version"`
+	Revisions map[string]revisionEntry `json:"revisions"`
+}
+
+// LoadRevisions restores the revisions of the races from the data
+// directory. Missing revisions are not an error.
+func LoadRevisions() error {
+	if dataDir == "" {
+		return nil
+	}
+
+	path := filepath.Join(dataDir, revisionsFilename)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	var file revisionsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("failed to decode revisions: %w", err)
+	}
+	if file.Version != revisionsVersion {
+		return fmt.Errorf("unsupported revisions version %d (expected %d)", file.Version, revisionsVersion)
+	}
+
+	revisions.Lock()
+	revisions.entries = file.Revisions
+	if revisions.entries == nil {
+		revisions.entries = map[string]revisionEntry{}
+	}
+	revisions.Unlock()
+
+	logger.Log.Info().Str("path", path).Int("count", len(file.Revisions)).Msg("Loaded race revisions from disk")
+	return nil
+}
+
+// applyRevisions sets the revision of every race by UID, bumping the sequence of
+// those whose start, end, title, stream info or cancellation changed since
+// they were last served, and persists the revisions when they changed
+func applyRevisions(races []types.TizRace, now time.Time) {
+	now = now.UTC().Truncate(time.Second)
+	today := now.Truncate(24 * time.Hour)
+
+	revisions.Lock()
+	defer revisions.Unlock()
+
+	dirty := false
+	for i := range races {
+		key := races[i].UID
+		fingerprint := raceFingerprint(races[i])
+		entry, ok := revisions.entries[key]
+		switch {
+		case !ok:
+			entry = revisionEntry{Revision: types.Revision{Created: now, LastModified: now}, Fingerprint: fingerprint}
+			dirty = true
+		case entry.Fingerprint != fingerprint:
+			entry.Sequence++
+			entry.LastModified = now
+			entry.Fingerprint = fingerprint
+			dirty = true
+		}
+		// Only a new day is worth a write
+		if !entry.LastSeen.Equal(today) {
+			entry.LastSeen = today
+			dirty = true
+		}
+		revisions.entries[key] = entry
+		races[i].Revision = entry.Revision
+	}
+
+	for key, entry := range revisions.entries {
+		if now.Sub(entry.LastSeen) > revisionRetention {
+			delete(revisions.entries, key)
+			dirty = true
+		}
+	}
+
+	if !dirty || dataDir == "" {
+		return
+	}
+
+	content, err := json.Marshal(revisionsFile{Version: revisionsVersion, Revisions: revisions.entries})
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode race revisions")
+		return
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, revisionsFilename), content); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to persist race revisions")
+	}
+}
+
+// raceFingerprint hashes the fields of a race shown by calendar clients:
+// its start and end, title, stream info and cancellation
+func raceFingerprint(race types.TizRace) string {
+	start := ""
+	if slot, _, ok := types.FirstSlot(race.Times, race.StartDate); ok && !race.AllDay {
+		start = slot.Time
+	}
+
+	fields := []string{
+		race.StartDate, race.EndDate, race.DatePrecision, fmt.Sprint(race.AllDay), start, race.Duration,
+		race.Name, race.Stage, strings.Join(race.Categories, ","),
+		race.StreamType, strings.Join(race.StreamLinks, " "), race.StreamLang,
+	}
+	// Only cancellations count, so the sequences of the races served before
+	// statuses existed are kept; TENTATIVE follows the stream type
+	if race.Status == types.StatusCancelled {
+		fields = append(fields, race.Status)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,89 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"testing"
+	"time"
+)
+
+// resetRevisions forgets every revision and persists them in a temporary directory
+func resetRevisions(t *testing.T) {
+	t.Helper()
+
+	previousDir := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = previousDir })
+
+	revisions.Lock()
+	revisions.entries = map[string]revisionEntry{}
+	revisions.Unlock()
+
+	uids.Lock()
+	uids.aliases, uids.known = map[string]string{}, map[string]uidIdentity{}
+	uids.Unlock()
+}
+
+// serve assigns the UIDs and revisions of races as GetRaces does
+func serve(races []types.TizRace, now time.Time) {
+	assignUIDs(races, now)
+	applyRevisions(races, now)
+}
+
+func TestApplyRevisions(t *testing.T) {
+	resetRevisions(t)
+
+	schedule := func(clock string, links ...string) []types.TizRace {
+		return []types.TizRace{
+			{Name: "Tour of Oman", Stage: "stage 1", StartDate: "2026-02-07", EndDate: "2026-02-07", Times: []types.TizTimeSlot{{Time: clock}}, StreamLinks: links},
+			{Name: "Muscat Classic", StartDate: "2026-02-06", EndDate: "2026-02-06", AllDay: true},
+		}
+	}
+	day := time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+
+	races := schedule("09:00:00 UTC")
+	serve(races, day)
+	if races[0].Revision.Sequence != 0 || !races[0].Revision.Created.Equal(day) || !races[0].Revision.LastModified.Equal(day) {
+		t.Fatalf("Unexpected first revision: %+v", races[0].Revision)
+	}
+
+	// Unchanged races keep their revision
+	races = schedule("09:00:00 UTC")
+	serve(races, day.Add(time.Hour))
+	if races[0].Revision.Sequence != 0 || !races[0].Revision.LastModified.Equal(day) {
+		t.Errorf("Expected an unchanged race to keep its revision, got %+v", races[0].Revision)
+	}
+
+	// A moved start time and a new stream link each bump the sequence
+	races = schedule("10:00:00 UTC")
+	serve(races, day.Add(2*time.Hour))
+	races = schedule("10:00:00 UTC", "https://www.youtube.com/live")
+	serve(races, day.Add(3*time.Hour))
+	if races[0].Revision.Sequence != 2 || !races[0].Revision.Created.Equal(day) || !races[0].Revision.LastModified.Equal(day.Add(3*time.Hour)) {
+		t.Errorf("Expected the sequence to be bumped twice, got %+v", races[0].Revision)
+	}
+	if races[1].Revision.Sequence != 0 {
+		t.Errorf("Expected the other race to be untouched, got %+v", races[1].Revision)
+	}
+
+	// Revisions survive a restart
+	revisions.Lock()
+	revisions.entries = map[string]revisionEntry{}
+	revisions.Unlock()
+	if err := LoadRevisions(); err != nil {
+		t.Fatalf("LoadRevisions returned error: %v", err)
+	}
+	races = schedule("10:00:00 UTC", "https://www.youtube.com/live")
+	serve(races, day.Add(4*time.Hour))
+	if races[0].Revision.Sequence != 2 || !races[0].Revision.Created.Equal(day) {
+		t.Errorf("Expected the revision to be restored, got %+v", races[0].Revision)
+	}
+
+	// Races gone for longer than the retention are forgotten
+	serve(races[:1], day.Add(revisionRetention+24*time.Hour))
+	revisions.Lock()
+	_, kept := revisions.entries["muscat-classic-20260206@cycling.for-loop.fr"]
+	revisions.Unlock()
+	if kept {
+		t.Error("Expected the revision of a race gone for over a year to be dropped")
+	}
+}
@@ -0,0 +1,158 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/metrics"
+	"cpe/calendar/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// snapshotVersion is bumped whenever the on-disk format or the meaning of
+	// a race field changes. Version 1 end dates were exclusive.
+	snapshotVersion  = 2
+	snapshotFilename = "tiz-snapshot.json"
+)
+
+var (
+	// dataDir is where persistent state is stored, empty disables persistence
+	dataDir = "data"
+)
+
+// Snapshot is the on-disk representation of the Tiz race cache
+type Snapshot struct {
+	Version      int             `json:"version"`
+	FetchedAt    time.Time       `json:"fetched_at"`
+	RawHTML      string          `json:"raw_html"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Races        []types.TizRace `json:"races"`
+}
+
+// DataDir returns the directory used for persistent state
+func DataDir() string {
+	return dataDir
+}
+
+// LoadSnapshot restores the race cache from the data directory. A missing
+// snapshot is not an error.
+func LoadSnapshot() error {
+	if dataDir == "" {
+		return nil
+	}
+
+	path := filepath.Join(dataDir, snapshotFilename)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Log.Info().Str("path", path).Msg("No Tiz snapshot on disk")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if err := upgradeSnapshot(&snapshot); err != nil {
+		return err
+	}
+	if len(snapshot.Races) == 0 {
+		return fmt.Errorf("snapshot contains no races")
+	}
+
+	raceCache.Lock()
+	raceCache.Races = snapshot.Races
+	raceCache.RawHTML = snapshot.RawHTML
+	raceCache.ETag = snapshot.ETag
+	raceCache.LastModified = snapshot.LastModified
+	raceCache.LastFetch = snapshot.FetchedAt
+	raceCache.Unlock()
+	sourcesRefreshed()
+
+	metrics.SnapshotTimestamp.Set(float64(snapshot.FetchedAt.Unix()))
+
+	logger.Log.Info().
+		Str("path", path).
+		Int("raceCount", len(snapshot.Races)).
+		Time("fetchedAt", snapshot.FetchedAt).
+		Msg("Loaded Tiz snapshot from disk")
+
+	return nil
+}
+
+// upgradeSnapshot brings a snapshot written by an older version up to date
+// by parsing its raw HTML again, as the meaning of its race fields changed
+func upgradeSnapshot(snapshot *Snapshot) error {
+	switch {
+	case snapshot.Version == snapshotVersion:
+		return nil
+	case snapshot.Version <= 0 || snapshot.Version > snapshotVersion:
+		return fmt.Errorf("unsupported snapshot version %d (expected %d)", snapshot.Version, snapshotVersion)
+	case snapshot.RawHTML == "":
+		return fmt.Errorf("snapshot version %d has no raw HTML to parse again", snapshot.Version)
+	}
+
+	races, _, err := parseTizRaces(snapshot.RawHTML, snapshot.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade snapshot version %d: %w", snapshot.Version, err)
+	}
+	logger.Log.Info().Int("from", snapshot.Version).Int("to", snapshotVersion).Int("raceCount", len(races)).Msg("Upgraded snapshot by parsing it again")
+
+	snapshot.Races, snapshot.Version = races, snapshotVersion
+	return nil
+}
+
+// saveSnapshot atomically writes the snapshot to the data directory
+func saveSnapshot(snapshot Snapshot) error {
+	if dataDir == "" {
+		return nil
+	}
+
+	snapshot.Version = snapshotVersion
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(dataDir, snapshotFilename), content)
+}
+
+// writeFileAtomic writes content to a temporary file and renames it over
+// path, so readers never observe a partially written file
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"cpe/calendar/types"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	feed := rawFeed(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(feed)
+	}))
+	defer server.Close()
+
+	resetRaceCache(t, server.URL)
+	races, err := refreshTizRaces(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, snapshotFilename)); err != nil {
+		t.Fatalf("Expected snapshot on disk: %v", err)
+	}
+
+	// Simulate a restart
+	raceCache.Lock()
+	raceCache.Races = nil
+	raceCache.RawHTML = ""
+	raceCache.LastFetch = time.Time{}
+	raceCache.Unlock()
+
+	if err := LoadSnapshot(); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	raceCache.RLock()
+	defer raceCache.RUnlock()
+	if len(raceCache.Races) != len(races) {
+		t.Errorf("Expected %d races after reload, got %d", len(races), len(raceCache.Races))
+	}
+	if raceCache.RawHTML != string(feed) {
+		t.Error("Expected raw HTML to be restored")
+	}
+	if raceCache.LastFetch.IsZero() {
+		t.Error("Expected fetch time to be restored")
+	}
+}
+
+func TestLoadSnapshotRejectsUnknownVersion(t *testing.T) {
+	resetRaceCache(t, tizURL)
+	content := []byte(`{"version": 99, "races": [{"name": "Future"}]}`)
+	if err := os.WriteFile(filepath.Join(dataDir, snapshotFilename), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadSnapshot(); err == nil {
+		t.Error("Expected an error for an unknown snapshot version")
+	}
+}
+
+// version1Snapshot is a snapshot written before end dates were inclusive:
+// the race held for 3 days from the 6th ended on the 9th
+func version1Snapshot() Snapshot {
+	return Snapshot{
+		Version:   1,
+		FetchedAt: time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC),
+		RawHTML:   `<ul><li><img src="es.png" /> Friday 6th February - Vuelta a Murcia (ME) - LIVE <strong><a href="https://example.org/live">Link</a></strong> (Spanish) - for 3 days - 10:00 UTC</li></ul>`,
+		Races:     []types.TizRace{{Name: "Vuelta a Murcia", StartDate: "2026-02-06", EndDate: "2026-02-09"}},
+	}
+}
+
+func TestLoadSnapshotUpgradesVersion1(t *testing.T) {
+	resetRaceCache(t, tizURL)
+	content, err := json.Marshal(version1Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, snapshotFilename), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadSnapshot(); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	raceCache.RLock()
+	defer raceCache.RUnlock()
+	if len(raceCache.Races) != 1 || raceCache.Races[0].EndDate != "2026-02-08" {
+		t.Fatalf("Expected the race to end on its last day after the upgrade, got %+v", raceCache.Races)
+	}
+	if got := raceCache.Races[0].Languages(); len(got) != 1 || got[0] != "es" {
+		t.Errorf("Expected the upgrade to tag the commentary languages, got %v", got)
+	}
+}
+
+func TestLoadArchivedUpgradesVersion1(t *testing.T) {
+	resetRaceCache(t, "")
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(version1Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	if err := writeFileAtomic(filepath.Join(archiveDir(), "20260204T060000Z"+archiveExt), buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := LoadArchived("20260204T060000Z")
+	if err != nil {
+		t.Fatalf("LoadArchived failed: %v", err)
+	}
+	if snapshot.Version != snapshotVersion || len(snapshot.Races) != 1 || snapshot.Races[0].EndDate != "2026-02-08" {
+		t.Errorf("Expected an upgraded snapshot, got version %d with %+v", snapshot.Version, snapshot.Races)
+	}
+}
+
+func TestLoadSnapshotRejectsVersion1WithoutHTML(t *testing.T) {
+	resetRaceCache(t, tizURL)
+	snapshot := version1Snapshot()
+	snapshot.RawHTML = ""
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, snapshotFilename), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadSnapshot(); err == nil {
+		t.Error("Expected an error for an old snapshot that cannot be parsed again")
+	}
+}
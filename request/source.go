@@ -0,0 +1,355 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/languages"
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RaceSource is a provider of races that can be merged into the calendar
+type RaceSource interface {
+	// Name returns the registry name of the source (e.g. "tiz")
+	Name() string
+	// Fetch returns the races currently known by the source
+	Fetch(ctx context.Context) ([]types.TizRace, error)
+}
+
+// WarningSource is implemented by sources that can serve degraded data
+type WarningSource interface {
+	// Warning returns a non-empty message when the data is degraded
+	Warning() string
+}
+
+// WatchingSource is implemented by sources that keep their races up to date
+// in the background
+type WatchingSource interface {
+	// Watch updates the races of the source until ctx is cancelled
+	Watch(ctx context.Context)
+}
+
+// SourceFactory builds a RaceSource from the registry
+type SourceFactory func() (RaceSource, error)
+
+var (
+	sourceRegistry = struct {
+		sync.RWMutex
+		factories map[string]SourceFactory
+	}{
+		factories: map[string]SourceFactory{
+			"tiz": func() (RaceSource, error) { return TizSource{}, nil },
+			"file": func() (RaceSource, error) {
+				if replayPath == "" {
+					return nil, fmt.Errorf("replay_path is not set")
+				}
+				return FileSource{}, nil
+			},
+		},
+	}
+
+	activeSources struct {
+		sync.RWMutex
+		sources []RaceSource
+	}
+
+	// sourcesGeneration counts the refreshes of the sources and overrides,
+	// starting at 1
+	sourcesGeneration = struct {
+		sync.Mutex
+		n uint64
+	}{n: 1}
+
+	// served holds the races of the active sources once enriched, built
+	// once per generation of the sources
+	served struct {
+		sync.Mutex
+		races      []types.TizRace
+		generation uint64 // 0 before the first build
+	}
+)
+
+// RegisterSource makes a race source available under the given name
+func RegisterSource(name string, factory SourceFactory) {
+	sourceRegistry.Lock()
+	defer sourceRegistry.Unlock()
+	sourceRegistry.factories[name] = factory
+}
+
+// RegisteredSources returns the sorted names of all registered sources
+func RegisteredSources() []string {
+	sourceRegistry.RLock()
+	defer sourceRegistry.RUnlock()
+
+	names := make([]string, 0, len(sourceRegistry.factories))
+	for name := range sourceRegistry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSources builds the sources matching the given registry names
+func NewSources(names []string) ([]RaceSource, error) {
+	sourceRegistry.RLock()
+	defer sourceRegistry.RUnlock()
+
+	var sources []RaceSource
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		factory, ok := sourceRegistry.factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown race source: %s", name)
+		}
+		source, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create race source %s: %w", name, err)
+		}
+		sources = append(sources, source)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no race source configured")
+	}
+
+	return sources, nil
+}
+
+// SetSources replaces the active race sources
+func SetSources(sources []RaceSource) {
+	activeSources.Lock()
+	defer activeSources.Unlock()
+	activeSources.sources = sources
+	sourcesRefreshed()
+
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.Name()
+	}
+	logger.Log.Info().Strs("sources", names).Msg("Configured race sources")
+}
+
+// StartSources runs the background updates of the active sources until ctx
+// is cancelled, returning once every update has stopped
+func StartSources(ctx context.Context) {
+	activeSources.RLock()
+	sources := activeSources.sources
+	activeSources.RUnlock()
+
+	var watchers sync.WaitGroup
+	for _, source := range sources {
+		if ws, ok := source.(WatchingSource); ok {
+			logger.Log.Info().Str("source", source.Name()).Msg("Starting race source updates")
+			watchers.Add(1)
+			go func() {
+				defer watchers.Done()
+				ws.Watch(ctx)
+			}()
+		}
+	}
+	watchers.Wait()
+}
+
+// GetRaces fetches and merges the races of every active source, returning
+// warnings for degraded or failing sources. The races are enriched once
+// per refresh of the sources or overrides and shared by every request.
+func GetRaces(ctx context.Context) ([]types.TizRace, []string, error) {
+	activeSources.RLock()
+	sources := activeSources.sources
+	activeSources.RUnlock()
+
+	if len(sources) == 0 {
+		sources = []RaceSource{TizSource{}}
+	}
+
+	// Read first, the races fetched are at least as recent
+	generation := currentGeneration()
+	races, warnings, err := MergeSources(ctx, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	served.Lock()
+	defer served.Unlock()
+	if served.generation < generation {
+		served.races = enrichRaces(races, time.Now())
+		served.generation = generation
+	}
+	races = make([]types.TizRace, len(served.races))
+	copy(races, served.races)
+	return races, warnings, nil
+}
+
+// prepareRaces enriches the races of the active sources after a background
+// refresh, so requests do not have to
+func prepareRaces(ctx context.Context) {
+	activeSources.RLock()
+	configured := len(activeSources.sources) > 0
+	activeSources.RUnlock()
+	if !configured {
+		return
+	}
+
+	if _, _, err := GetRaces(ctx); err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to prepare races after a refresh")
+	}
+}
+
+// sourcesRefreshed makes the next GetRaces enrich the races again
+func sourcesRefreshed() {
+	sourcesGeneration.Lock()
+	sourcesGeneration.n++
+	sourcesGeneration.Unlock()
+}
+
+// currentGeneration returns the generation of the sources and overrides
+func currentGeneration() uint64 {
+	sourcesGeneration.Lock()
+	defer sourcesGeneration.Unlock()
+	return sourcesGeneration.n
+}
+
+// enrichRaces completes the fields older sources lack, assigns the UIDs of
+// the merged races, applies the overrides to them and assigns their
+// cancellations and revisions, persisting them in the data directory
+func enrichRaces(races []types.TizRace, now time.Time) []types.TizRace {
+	for i := range races {
+		completeRace(&races[i])
+	}
+	// Overrides match the UIDs served, the races they add get theirs after
+	assignUIDs(races, now)
+	races = applyOverrides(races)
+	for i := range races {
+		completeRace(&races[i])
+	}
+	assignUIDs(races, now)
+	races = retainCancelled(races, now)
+	applyRevisions(races, now)
+	return races
+}
+
+// completeRace fills the fields of races of sources or snapshots that do not
+// classify them, unless overridden
+func completeRace(race *types.TizRace) {
+	if race.Discipline == "" {
+		race.Discipline = classifyDiscipline(*race)
+	}
+	if race.Classification == "" {
+		race.Classification = classifyRace(*race)
+	}
+	if race.Status == "" {
+		race.Status, race.StatusNote = parseStatus(race.Name, race.StreamType)
+	}
+	backfillLinks(race)
+}
+
+// backfillLinks gives the races of sources that only know stream URLs their
+// links, and tags the commentary languages written without them
+func backfillLinks(race *types.TizRace) {
+	if len(race.Links) == 0 {
+		for i, url := range race.StreamLinks {
+			link := types.TizLink{URL: url, Kind: types.LinkKindDirect}
+			if i == 0 {
+				link.Language = race.StreamLang
+			}
+			race.Links = append(race.Links, link)
+		}
+	}
+	cloned := false
+	for i, link := range race.Links {
+		if link.Language == "" || link.Languages != nil {
+			continue
+		}
+		// The links are shared with the cache of the source
+		if !cloned {
+			race.Links, cloned = slices.Clone(race.Links), true
+		}
+		race.Links[i].Languages = languages.Parse(link.Language)
+	}
+}
+
+// MergeSources fetches every source concurrently and merges their races.
+// A failing source is logged, skipped and reported as a warning; an error
+// is only returned when every source failed.
+func MergeSources(ctx context.Context, sources []RaceSource) ([]types.TizRace, []string, error) {
+	results := make([][]types.TizRace, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source RaceSource) {
+			defer wg.Done()
+			results[i], errs[i] = source.Fetch(ctx)
+		}(i, source)
+	}
+	wg.Wait()
+
+	var lastErr error
+	var warnings []string
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			logger.Log.Error().Err(err).Str("source", sources[i].Name()).Msg("Race source failed")
+			warnings = append(warnings, fmt.Sprintf("%s: source unavailable", sources[i].Name()))
+			lastErr = err
+			failed++
+			continue
+		}
+		if ws, ok := sources[i].(WarningSource); ok {
+			if warning := ws.Warning(); warning != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", sources[i].Name(), warning))
+			}
+		}
+		for j := range results[i] {
+			if results[i][j].Source == "" {
+				results[i][j].Source = sources[i].Name()
+			}
+		}
+	}
+
+	if failed == len(sources) {
+		return nil, nil, fmt.Errorf("all race sources failed: %w", lastErr)
+	}
+
+	return MergeRaces(results...), warnings, nil
+}
+
+// MergeRaces combines race lists, skipping the races of a list already in
+// an earlier one. Races of a single list are all kept, the same name may
+// be listed twice on a day.
+func MergeRaces(lists ...[]types.TizRace) []types.TizRace {
+	var merged []types.TizRace
+	seen := make(map[string]bool)
+
+	for _, races := range lists {
+		var keys []string
+		for _, race := range races {
+			key := raceMergeKey(race)
+			if seen[key] {
+				continue
+			}
+			keys = append(keys, key)
+			merged = append(merged, race)
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+	}
+
+	return merged
+}
+
+// raceMergeKey identifies the same race across sources
+func raceMergeKey(race types.TizRace) string {
+	return strings.ToLower(strings.Join(strings.Fields(race.Name), " ")) + "|" +
+		strings.ToLower(race.Stage) + "|" + race.StartDate
+}
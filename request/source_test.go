@@ -0,0 +1,155 @@
+package request
+
+import (
+	"context"
+	"cpe/calendar/types"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type staticSource struct {
+	name  string
+	races []types.TizRace
+	err   error
+}
+
+func (s staticSource) Name() string {
+	return s.name
+}
+
+func (s staticSource) Fetch(ctx context.Context) ([]types.TizRace, error) {
+	return s.races, s.err
+}
+
+func TestMergeSources(t *testing.T) {
+	first := staticSource{name: "first", races: []types.TizRace{
+		{Name: "Tour of Oman", StartDate: "2026-02-07"},
+		{Name: "Muscat Classic", StartDate: "2026-02-06"},
+	}}
+	second := staticSource{name: "second", races: []types.TizRace{
+		{Name: "Tour  of Oman", StartDate: "2026-02-07"},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28"},
+	}}
+	broken := staticSource{name: "broken", err: errors.New("unreachable")}
+
+	races, warnings, err := MergeSources(context.Background(), []RaceSource{first, broken, second})
+	if err != nil {
+		t.Fatalf("MergeSources returned error: %v", err)
+	}
+
+	if len(races) != 3 {
+		t.Fatalf("Expected 3 merged races, got %d", len(races))
+	}
+	if races[0].Source != "first" {
+		t.Errorf("Expected duplicate race to come from first source, got %s", races[0].Source)
+	}
+	if races[2].Source != "second" {
+		t.Errorf("Expected last race to come from second source, got %s", races[2].Source)
+	}
+
+	if len(warnings) != 1 {
+		t.Errorf("Expected a warning for the failing source, got %v", warnings)
+	}
+
+	if _, _, err := MergeSources(context.Background(), []RaceSource{broken}); err == nil {
+		t.Error("Expected an error when every source fails")
+	}
+}
+
+func TestMergeRacesKeepsRacesOfOneSource(t *testing.T) {
+	first := []types.TizRace{
+		{Name: "Cyclo-cross", StartDate: "2026-02-07", Country: "BE"},
+		{Name: "Cyclo-Cross", StartDate: "2026-02-07", Country: "NL"},
+	}
+	second := []types.TizRace{
+		{Name: "Cyclo-cross", StartDate: "2026-02-07", Country: "FR"},
+	}
+
+	races := MergeRaces(first, second)
+	if len(races) != 2 || races[0].Country != "BE" || races[1].Country != "NL" {
+		t.Errorf("Expected both races of the first source only, got %+v", races)
+	}
+}
+
+func TestNewSourcesUnknown(t *testing.T) {
+	if _, err := NewSources([]string{"tiz", "nope"}); err == nil {
+		t.Error("Expected an error for an unknown source name")
+	}
+}
+
+func TestBackfillLinks(t *testing.T) {
+	race := types.TizRace{
+		StreamLinks: []string{"https://example.org/live", "https://example.org/other"},
+		StreamLang:  "English or Spanish",
+	}
+	backfillLinks(&race)
+
+	if len(race.Links) != 2 || race.Links[0].Kind != types.LinkKindDirect || race.Links[1].Language != "" {
+		t.Fatalf("Expected a direct link per stream URL, got %+v", race.Links)
+	}
+	if got := race.Languages(); !slices.Equal(got, []string{"en", "es"}) {
+		t.Errorf("Expected the languages of the stream, got %v", got)
+	}
+
+	parsed := types.TizRace{Links: []types.TizLink{{URL: "https://example.org/tiz", Kind: types.LinkKindStream, Language: "Flemish"}}}
+	cached := parsed
+	backfillLinks(&parsed)
+	if got := parsed.Languages(); !slices.Equal(got, []string{"nl-BE"}) {
+		t.Errorf("Expected the languages of the link to be tagged, got %v", got)
+	}
+	if cached.Links[0].Languages != nil {
+		t.Errorf("Expected the links of the cached race to be left alone, got %+v", cached.Links)
+	}
+}
+
+// countingSource numbers its races after the fetches
+type countingSource struct {
+	fetches int
+}
+
+func (s *countingSource) Name() string {
+	return "counting"
+}
+
+func (s *countingSource) Fetch(ctx context.Context) ([]types.TizRace, error) {
+	s.fetches++
+	return []types.TizRace{{Name: "Tour of Oman", StartDate: "2026-02-07", Notes: strconv.Itoa(s.fetches)}}, nil
+}
+
+func TestGetRacesEnrichesOncePerRefresh(t *testing.T) {
+	resetRevisions(t)
+	resetCancellations(t)
+	resetOverrides(t, "")
+
+	activeSources.RLock()
+	previous := activeSources.sources
+	activeSources.RUnlock()
+	t.Cleanup(func() { SetSources(previous) })
+
+	source := &countingSource{}
+	SetSources([]RaceSource{source})
+
+	notes := func() string {
+		races, _, err := GetRaces(context.Background())
+		if err != nil {
+			t.Fatalf("GetRaces returned error: %v", err)
+		}
+		if len(races) != 1 || races[0].UID == "" {
+			t.Fatalf("Expected an enriched race, got %+v", races)
+		}
+		return races[0].Notes
+	}
+
+	if got := notes(); got != "1" {
+		t.Fatalf("Expected the races of the first fetch, got %q", got)
+	}
+	if got := notes(); got != "1" {
+		t.Errorf("Expected the enriched races to be reused until a refresh, got %q", got)
+	}
+	sourcesRefreshed()
+	if got := notes(); got != "3" {
+		t.Errorf("Expected the races to be enriched again after a refresh, got %q", got)
+	}
+}
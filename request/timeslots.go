@@ -0,0 +1,238 @@
+package request
+
+import (
+	"cpe/calendar/dates"
+	"cpe/calendar/types"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLabelWords bounds the length of a slot label, days excluded. Longer text
+// before a time is prose (e.g. a note saying "starts around 10.00 UTC").
+const maxLabelWords = 3
+
+var (
+	// "12.40 UTC", "9:05 CEST (90 mins)", "14h00 local time", "14:00"
+	slotTimePattern = regexp.MustCompile(`\b(\d{1,2})([.:h])(\d{2})\b(?:\s*(` + zoneExpr() + `))?(?:\s*\(([^)]*(?:min|hr|hour)[^)]*)\))?`)
+	// Units showing a number is a duration ("3.25 hrs"), not a time
+	durationUnitPattern = regexp.MustCompile(`^\s*(?:hrs?|hours?|mins?|minutes?)\b`)
+	// Separators between the label of a slot and what precedes it
+	slotSeparatorPattern = regexp.MustCompile(`\s+[-–]\s+|[,;/&()|]|\band\b`)
+	// Gap between two slots sharing a label: "ME 10.00 UTC, 14.00 UTC"
+	slotContinuationPattern = regexp.MustCompile(`^\s*(?:,|&|/|and)?\s*$`)
+	// "stage 2", "stages 2-4", "days 1 & 2"
+	slotStagePattern = regexp.MustCompile(`(?i)\b(?:stages?|days?)\s+(\d+)(?:\s*(?:-|–|to|&|and)\s*(\d+))?`)
+	// "Saturday", "Sat 7th", "Saturday 7th February", "7th February", "7th"
+	slotDatePattern = regexp.MustCompile(`(?i)\b(?:` +
+		`(Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday|Mon|Tue|Wed|Thu|Fri|Sat|Sun)\b\.?(?:\s+(\d{1,2})(?:st|nd|rd|th)?)?(?:\s+` + monthExpr + `)?` +
+		`|(\d{1,2})(?:st|nd|rd|th)(?:\s+` + monthExpr + `)?` +
+		`|(\d{1,2})\s+` + monthExpr +
+		`)`)
+	// Current stage or day of a race, e.g. "stage 1 (of 4)"
+	raceStagePattern = regexp.MustCompile(`(?:stage|day)\s+(\d+)`)
+)
+
+// extractTimes extracts the time slots of an entry. Each time may be preceded
+// by a label naming a category ("WE", "Men U23", "Track"), a session
+// ("Heats", "Finals") and/or the days it applies to ("stages 2-4", "Sat").
+// A time following another one with only a comma in between shares its label.
+func extractTimes(text string) []types.TizTimeSlot {
+	var timeSlots []types.TizTimeSlot
+
+	prevEnd := 0
+	for _, match := range slotTimePattern.FindAllStringSubmatchIndex(text, -1) {
+		prefix := text[prevEnd:match[0]]
+		hour, _ := strconv.Atoi(text[match[2]:match[3]])
+		separator := text[match[4]:match[5]]
+		minute, _ := strconv.Atoi(text[match[6]:match[7]])
+		zone := ""
+		if match[8] >= 0 {
+			zone = normalizeZone(text[match[8]:match[9]])
+		}
+		duration := ""
+		if match[10] >= 0 {
+			duration = strings.TrimSpace(text[match[10]:match[11]])
+		}
+
+		if hour > 23 || minute > 59 || durationUnitPattern.MatchString(text[match[1]:]) {
+			continue
+		}
+		// Without a zone only clock notations are times, "10.30" may be a number
+		if zone == "" && separator == "." {
+			continue
+		}
+		prevEnd = match[1]
+
+		slot := types.TizTimeSlot{
+			Time:     strings.TrimSpace(fmt.Sprintf("%02d:%02d:00 %s", hour, minute, zone)),
+			Zone:     zone,
+			Duration: duration,
+		}
+
+		label := slotLabel(prefix)
+		if label == "" && len(timeSlots) > 0 && slotContinuationPattern.MatchString(prefix) {
+			previous := timeSlots[len(timeSlots)-1]
+			slot.Category, slot.Label, slot.Day = previous.Category, previous.Label, previous.Day
+		} else {
+			slot.Label, slot.Day = splitSlotDay(label)
+			if len(strings.Fields(slot.Label)) > maxLabelWords {
+				continue
+			}
+			slot.Category = slotCategory(slot.Label)
+		}
+
+		timeSlots = append(timeSlots, slot)
+	}
+
+	return timeSlots
+}
+
+// slotLabel returns the text between the last separator of prefix and the time
+func slotLabel(prefix string) string {
+	parts := slotSeparatorPattern.Split(prefix, -1)
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(parts[len(parts)-1]), ":"))
+}
+
+// splitSlotDay separates the day specification of a label ("stages 2-4",
+// "Sat 7th") from the rest of it
+func splitSlotDay(label string) (rest, day string) {
+	for _, pattern := range []*regexp.Regexp{slotStagePattern, slotDatePattern} {
+		if loc := pattern.FindStringIndex(label); loc != nil {
+			day = strings.TrimSpace(label[loc[0]:loc[1]])
+			label = label[:loc[0]] + " " + label[loc[1]:]
+			return strings.Join(strings.Fields(label), " "), day
+		}
+	}
+	return label, ""
+}
+
+// slotCategory returns the types.TizCategoryMap code named by a label, as a
+// code or a display name, or an empty string when it names none
+func slotCategory(label string) string {
+	if label == "" {
+		return ""
+	}
+	if code, ok := categoryCode(label); ok {
+		return code
+	}
+	for _, word := range strings.Fields(label) {
+		if code, ok := categoryCode(word); ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// categoryCode looks a category up by code or display name, ignoring case
+func categoryCode(name string) (string, bool) {
+	for code, displayName := range types.TizCategoryMap {
+		if strings.EqualFold(name, code) || strings.EqualFold(name, displayName) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// resolveSlotDates sets the dates of the slots restricted to some days of the
+// race. Stage and day numbers count from the stage shown on the start date,
+// weekdays and days of the month are the first match on or after it.
+func resolveSlotDates(race *types.TizRace, ref time.Time) {
+	if race.DatePrecision != types.DatePrecisionDay {
+		return
+	}
+	start, err := dates.Parse(race.StartDate)
+	if err != nil {
+		return
+	}
+
+	current := 1
+	if m := raceStagePattern.FindStringSubmatch(race.Stage); m != nil {
+		current, _ = strconv.Atoi(m[1])
+	}
+
+	for i := range race.Times {
+		slot := &race.Times[i]
+		if slot.Day == "" {
+			continue
+		}
+
+		if m := slotStagePattern.FindStringSubmatch(slot.Day); m != nil {
+			first, _ := strconv.Atoi(m[1])
+			last := first
+			if m[2] != "" {
+				last, _ = strconv.Atoi(m[2])
+			}
+			// Guard against typos turning a slot into months of dates
+			if last < first || last-first > 31 {
+				continue
+			}
+			for n := first; n <= last; n++ {
+				slot.Dates = append(slot.Dates, dates.Format(start.AddDate(0, 0, n-current)))
+			}
+			continue
+		}
+
+		if date, ok := resolveSlotDate(slot.Day, start, ref); ok {
+			slot.Dates = []string{dates.Format(date)}
+		}
+	}
+}
+
+// resolveSlotDate resolves a weekday or day of the month relative to the
+// start of a race
+func resolveSlotDate(day string, start, ref time.Time) (time.Time, bool) {
+	m := slotDatePattern.FindStringSubmatch(day)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	dayOfMonth, monthName := m[2], m[3]
+	if m[4] != "" {
+		dayOfMonth, monthName = m[4], m[5]
+	} else if m[6] != "" {
+		dayOfMonth, monthName = m[6], m[7]
+	}
+
+	if dayOfMonth == "" {
+		// Weekday only
+		for offset := 0; offset < 7; offset++ {
+			date := start.AddDate(0, 0, offset)
+			if strings.EqualFold(date.Weekday().String()[:3], m[1][:3]) {
+				return date, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	if monthName != "" {
+		return resolveDayMonth(dayOfMonth, capitalize(monthName), ref)
+	}
+
+	n, err := strconv.Atoi(dayOfMonth)
+	if err != nil {
+		return time.Time{}, false
+	}
+	date := time.Date(start.Year(), start.Month(), n, 0, 0, 0, 0, time.UTC)
+	if date.Before(start) {
+		date = time.Date(start.Year(), start.Month()+1, n, 0, 0, 0, 0, time.UTC)
+	}
+	return date, date.Day() == n
+}
+
+// capitalize upper-cases the first letter of an English word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+}
+
+// calculateDurationFromTimes returns the duration of the slot a race
+// starting on the given date starts with
+func calculateDurationFromTimes(times []types.TizTimeSlot, date string) string {
+	slot, _, _ := types.FirstSlot(times, date)
+	return slot.Duration
+}
@@ -0,0 +1,220 @@
+package request
+
+import (
+	"cpe/calendar/dates"
+	"cpe/calendar/types"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zoneAbbreviation maps a time zone abbreviation to the tz database location
+// observing it. dst tells whether the abbreviation names summer time.
+type zoneAbbreviation struct {
+	location string
+	dst      bool
+}
+
+var (
+	zoneAbbreviations = map[string]zoneAbbreviation{
+		"UTC":  {"UTC", false},
+		"GMT":  {"UTC", false},
+		"Z":    {"UTC", false},
+		"WET":  {"Europe/Lisbon", false},
+		"WEST": {"Europe/Lisbon", true},
+		"BST":  {"Europe/London", true},
+		"CET":  {"Europe/Paris", false},
+		"CEST": {"Europe/Paris", true},
+		"EET":  {"Europe/Athens", false},
+		"EEST": {"Europe/Athens", true},
+		"EST":  {"America/New_York", false},
+		"EDT":  {"America/New_York", true},
+		"PST":  {"America/Los_Angeles", false},
+		"PDT":  {"America/Los_Angeles", true},
+		"AEST": {"Australia/Sydney", false},
+		"AEDT": {"Australia/Sydney", true},
+		"NZST": {"Pacific/Auckland", false},
+		"NZDT": {"Pacific/Auckland", true},
+	}
+
+	// countryTimezones maps the ISO 3166 code of a race country to its main
+	// tz database location, used for "local time"
+	countryTimezones = map[string]string{
+		"AD": "Europe/Andorra", "AE": "Asia/Dubai", "AR": "America/Argentina/Buenos_Aires",
+		"AT": "Europe/Vienna", "AU": "Australia/Sydney", "BE": "Europe/Brussels",
+		"BR": "America/Sao_Paulo", "CA": "America/Toronto", "CH": "Europe/Zurich",
+		"CL": "America/Santiago", "CN": "Asia/Shanghai", "CO": "America/Bogota",
+		"CZ": "Europe/Prague", "DE": "Europe/Berlin", "DK": "Europe/Copenhagen",
+		"EC": "America/Guayaquil", "ES": "Europe/Madrid", "FI": "Europe/Helsinki",
+		"FR": "Europe/Paris", "GB": "Europe/London", "GR": "Europe/Athens",
+		"GT": "America/Guatemala", "HR": "Europe/Zagreb", "HU": "Europe/Budapest",
+		"IE": "Europe/Dublin", "IL": "Asia/Jerusalem", "IT": "Europe/Rome",
+		"JP": "Asia/Tokyo", "KR": "Asia/Seoul", "LU": "Europe/Luxembourg",
+		"MA": "Africa/Casablanca", "MX": "America/Mexico_City", "NL": "Europe/Amsterdam",
+		"NO": "Europe/Oslo", "NZ": "Pacific/Auckland", "OM": "Asia/Muscat",
+		"PL": "Europe/Warsaw", "PT": "Europe/Lisbon", "QA": "Asia/Qatar",
+		"RO": "Europe/Bucharest", "RW": "Africa/Kigali", "SA": "Asia/Riyadh",
+		"SE": "Europe/Stockholm", "SI": "Europe/Ljubljana", "SK": "Europe/Bratislava",
+		"TR": "Europe/Istanbul", "US": "America/New_York",
+		"ZA": "Africa/Johannesburg",
+	}
+
+	// multiZoneCountries span several time zones, so "local time" there is a guess
+	multiZoneCountries = map[string]bool{
+		"AU": true, "BR": true, "CA": true, "MX": true, "US": true,
+	}
+
+	// "UTC+1", "GMT+05:30", "UTC-3"
+	utcOffsetPattern = regexp.MustCompile(`^(?:UTC|GMT)\s*([+-])\s*(\d{1,2})(?::?(\d{2}))?$`)
+)
+
+// isZoneName tells whether text is only the name of a time zone (UTC, CET)
+func isZoneName(text string) bool {
+	_, ok := zoneAbbreviations[strings.ToUpper(strings.TrimSpace(text))]
+	return ok
+}
+
+// zoneExpr matches the zones understood next to a time: abbreviations, UTC
+// offsets and "local time"
+func zoneExpr() string {
+	names := make([]string, 0, len(zoneAbbreviations))
+	for name := range zoneAbbreviations {
+		names = append(names, name)
+	}
+	// Longest first, so CEST is not read as CET
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	return `(?:UTC|GMT)\s*[+-]\s*\d{1,2}(?::?\d{2})?\b|(?:` + strings.Join(names, "|") + `)\b|(?i:local(?:\s+time)?)\b`
+}
+
+// normalizeZone returns the canonical spelling of a zone captured next to a time
+func normalizeZone(zone string) string {
+	zone = strings.Join(strings.Fields(zone), " ")
+	if strings.HasPrefix(strings.ToLower(zone), "local") {
+		return "local time"
+	}
+	return strings.ToUpper(strings.ReplaceAll(zone, " ", ""))
+}
+
+// zoneLocation resolves the zone written next to a time on the given date.
+// The returned ambiguity explains why the location is a guess and is empty
+// when the zone is certain.
+func zoneLocation(zone, country string, date time.Time) (*time.Location, string) {
+	switch {
+	case zone == "":
+		// The schedule announces UTC as its convention
+		return time.UTC, "no time zone given, UTC assumed"
+
+	case zone == "local time":
+		name, ok := countryTimezones[strings.ToUpper(country)]
+		if !ok {
+			return time.UTC, fmt.Sprintf("local time of unknown country %q, UTC assumed", country)
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return time.UTC, fmt.Sprintf("time zone %s unavailable, UTC assumed", name)
+		}
+		if multiZoneCountries[strings.ToUpper(country)] {
+			return loc, fmt.Sprintf("%s spans several time zones, %s assumed", country, name)
+		}
+		return loc, ""
+	}
+
+	if m := utcOffsetPattern.FindStringSubmatch(zone); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes := 0
+		if m[3] != "" {
+			minutes, _ = strconv.Atoi(m[3])
+		}
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(zone, offset), ""
+	}
+
+	abbreviation, ok := zoneAbbreviations[zone]
+	if !ok {
+		return time.UTC, fmt.Sprintf("unknown time zone %q, UTC assumed", zone)
+	}
+	loc, err := time.LoadLocation(abbreviation.location)
+	if err != nil {
+		return time.UTC, fmt.Sprintf("time zone %s unavailable, UTC assumed", abbreviation.location)
+	}
+	if abbreviation.location != "UTC" {
+		// Summer time abbreviations are often used all year round
+		noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, loc)
+		if noon.IsDST() != abbreviation.dst {
+			return loc, fmt.Sprintf("%s is not in effect on %s, local time of %s assumed", zone, dates.Format(date), abbreviation.location)
+		}
+	}
+	return loc, ""
+}
+
+// localTime builds the wall clock time of a date in loc, reporting clocks
+// skipped or repeated by a daylight saving change
+func localTime(date time.Time, hour, minute int, loc *time.Location) (time.Time, string) {
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	if t.Hour() != hour || t.Minute() != minute {
+		return t, fmt.Sprintf("%02d:%02d does not exist in %s on %s", hour, minute, loc, dates.Format(date))
+	}
+
+	// Around a change, the wall clock may exist at both offsets
+	_, before := t.Add(-2 * time.Hour).Zone()
+	_, after := t.Add(2 * time.Hour).Zone()
+	if before != after {
+		wall := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, time.UTC)
+		var matches []time.Time
+		for _, offset := range []int{before, after} {
+			if candidate := wall.Add(-time.Duration(offset) * time.Second).In(loc); candidate.Hour() == hour && candidate.Minute() == minute {
+				matches = append(matches, candidate)
+			}
+		}
+		if len(matches) == 2 {
+			// Keep the first occurrence, time.Date does not guarantee one
+			first := matches[0]
+			if matches[1].Before(first) {
+				first = matches[1]
+			}
+			return first, fmt.Sprintf("%02d:%02d happens twice in %s on %s", hour, minute, loc, dates.Format(date))
+		}
+	}
+
+	return t, ""
+}
+
+// resolveSlotTimes pins the time slots of a race to instants, in the zone
+// written next to each time on the first day the slot is held
+func resolveSlotTimes(race *types.TizRace) {
+	for i := range race.Times {
+		slot := &race.Times[i]
+
+		day := race.StartDate
+		if len(slot.Dates) > 0 {
+			day = slot.Dates[0]
+		}
+		date, err := dates.Parse(day)
+		if err != nil {
+			continue
+		}
+
+		var hour, minute int
+		if _, err := fmt.Sscanf(slot.Time, "%d:%d", &hour, &minute); err != nil {
+			continue
+		}
+
+		loc, ambiguity := zoneLocation(slot.Zone, race.Country, date)
+		start, clockAmbiguity := localTime(date, hour, minute, loc)
+		if ambiguity == "" {
+			ambiguity = clockAmbiguity
+		}
+
+		slot.Start = start
+		slot.Location = loc.String()
+		slot.Ambiguity = ambiguity
+		slot.Time = start.UTC().Format("15:04:05") + " UTC"
+	}
+}
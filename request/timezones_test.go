@@ -0,0 +1,105 @@
+package request
+
+import (
+	"cpe/calendar/countries"
+	"cpe/calendar/types"
+	"strings"
+	"testing"
+)
+
+func TestResolveSlotTimes(t *testing.T) {
+	tests := []struct {
+		text      string
+		country   string
+		date      string
+		utc       string
+		location  string
+		ambiguous string
+	}{
+		{"Race - ME 14.00 UTC (2 hrs)", "FR", "2026-06-10", "14:00:00 UTC", "UTC", ""},
+		{"Race - ME 14.00 GMT", "GB", "2026-06-10", "14:00:00 UTC", "UTC", ""},
+		{"Race - 14:00 CEST (2 hrs)", "FR", "2026-06-10", "12:00:00 UTC", "Europe/Paris", ""},
+		{"Race - 14h00 CET", "FR", "2026-02-04", "13:00:00 UTC", "Europe/Paris", ""},
+		{"Race - 14:00 CET", "FR", "2026-06-10", "12:00:00 UTC", "Europe/Paris", "CET is not in effect"},
+		{"Race - 14.00 BST", "GB", "2026-06-10", "13:00:00 UTC", "Europe/London", ""},
+		{"Race - 10.00 UTC+2", "RW", "2026-02-04", "08:00:00 UTC", "UTC+2", ""},
+		{"Race - 14.00 local time", "ES", "2026-02-04", "13:00:00 UTC", "Europe/Madrid", ""},
+		{"Race - 14.00 local time", "NZ", "2026-02-04", "01:00:00 UTC", "Pacific/Auckland", ""},
+		{"Race - 14.00 local time", "US", "2026-06-10", "18:00:00 UTC", "America/New_York", "spans several time zones"},
+		{"Race - 14.00 local time", "XX", "2026-06-10", "14:00:00 UTC", "UTC", "unknown country"},
+		{"Race - 14:00", "FR", "2026-06-10", "14:00:00 UTC", "UTC", "no time zone given"},
+		{"Race - 02:30 local time", "FR", "2026-03-29", "01:30:00 UTC", "Europe/Paris", "does not exist"},
+		{"Race - 02:30 local time", "FR", "2026-10-25", "00:30:00 UTC", "Europe/Paris", "happens twice"},
+	}
+
+	for _, tt := range tests {
+		race := types.TizRace{
+			Country:       tt.country,
+			StartDate:     tt.date,
+			DatePrecision: types.DatePrecisionDay,
+			Times:         extractTimes(tt.text),
+		}
+		resolveSlotTimes(&race)
+
+		if len(race.Times) != 1 {
+			t.Errorf("%s (%s): expected 1 slot, got %+v", tt.text, tt.country, race.Times)
+			continue
+		}
+		slot := race.Times[0]
+		if slot.Time != tt.utc {
+			t.Errorf("%s (%s): expected %s, got %s", tt.text, tt.country, tt.utc, slot.Time)
+		}
+		if slot.Location != tt.location {
+			t.Errorf("%s (%s): expected location %s, got %s", tt.text, tt.country, tt.location, slot.Location)
+		}
+		if tt.ambiguous == "" && slot.Ambiguity != "" {
+			t.Errorf("%s (%s): expected no ambiguity, got %q", tt.text, tt.country, slot.Ambiguity)
+		}
+		if tt.ambiguous != "" && !strings.Contains(slot.Ambiguity, tt.ambiguous) {
+			t.Errorf("%s (%s): expected ambiguity %q, got %q", tt.text, tt.country, tt.ambiguous, slot.Ambiguity)
+		}
+	}
+}
+
+func TestExtractTimesIgnoresNumbers(t *testing.T) {
+	slots := extractTimes("Race - 15.20 UTC (3.25 hrs) - Info - about 10.30 hrs of racing, 2.45 km climb")
+	if len(slots) != 1 || slots[0].Time != "15:20:00 UTC" || slots[0].Duration != "3.25 hrs" {
+		t.Errorf("Expected a single 15:20 slot of 3.25 hrs, got %+v", slots)
+	}
+}
+
+func TestTimeSlotOnKeepsWallClockAcrossDST(t *testing.T) {
+	race := types.TizRace{
+		Country:       "FR",
+		StartDate:     "2026-03-27",
+		DatePrecision: types.DatePrecisionDay,
+		Times:         extractTimes("Race - 14.00 CET"),
+	}
+	resolveSlotTimes(&race)
+
+	for date, expected := range map[string]string{
+		"2026-03-27": "13:00",
+		"2026-03-30": "12:00",
+	} {
+		start, err := race.Times[0].On(date)
+		if err != nil {
+			t.Fatalf("On(%s) returned error: %v", date, err)
+		}
+		if got := start.UTC().Format("15:04"); got != expected {
+			t.Errorf("On(%s): expected %s UTC, got %s", date, expected, got)
+		}
+	}
+}
+
+func TestCountryTimezonesKeys(t *testing.T) {
+	for code := range countryTimezones {
+		if country, ok := countries.Lookup(code); !ok || country.Alpha2 != code {
+			t.Errorf("%s is not an ISO 3166 alpha-2 code", code)
+		}
+	}
+	for code := range multiZoneCountries {
+		if _, ok := countryTimezones[code]; !ok {
+			t.Errorf("Multi-zone country %s has no time zone", code)
+		}
+	}
+}
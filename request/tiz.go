@@ -1,83 +1,126 @@
 package request
 
 import (
+	"context"
+	"cpe/calendar/countries"
+	"cpe/calendar/dates"
+	"cpe/calendar/languages"
 	"cpe/calendar/logger"
 	"cpe/calendar/types"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"golang.org/x/net/html"
 )
 
-const (
+var (
 	tizURL    = "https://cyclingtiz.live/sys-parse.php?file=db/races.txt"
-	userAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:147.0) Gecko/20100101 Firefox/147.0"
+	userAgent = "cycling-calendar/1.0 (+https://github.com/loan-mgt/cycling-calendar)"
+	// tizStreamPageURL is where the stream page the feed names without a link is reached
+	tizStreamPageURL = "https://cyclingtiz.live/"
+	// "(Spanish)", "(English or Spanish)" right after a link
+	linkLanguagePattern = regexp.MustCompile(`^\s*\(([^()]+)\)`)
+	// File name of a flag image: fr.png, B_be.png, UAE_ae.png, DEU.svg, uy.webp
+	flagFilePattern = regexp.MustCompile(`(?i)(?:^|/)([a-z]{1,3}(?:_[a-z]{2,3})?)\.(?:png|webp|svg|gif|jpe?g)(?:[?#].*)?$`)
+	// Mark of a race not held as scheduled, as a part of the entry of its own
+	// ("- CANCELLED -") or in brackets after the name ("(postponed)"). Words
+	// inside a sentence, such as a note on a past edition, are not marks.
+	statusPattern = regexp.MustCompile(`(?i)(?:^|\s*-\s*|\s*[(\[]\s*)(cancell?ed|postponed|neutrali[sz]ed)\s*(?:[)\]]|-|$)`)
+
+	// errNoRaces is returned when the upstream page parses to an empty schedule
+	errNoRaces = errors.New("no races found in upstream schedule")
 )
 
-var (
-	raceCache struct {
-		sync.RWMutex
-		Races     []types.TizRace
-		LastFetch time.Time
-	}
-)
+// TizSource is the RaceSource backed by the cyclingtiz.live schedule
+type TizSource struct{}
+
+// Name returns the registry name of the Tiz source
+func (TizSource) Name() string {
+	return "tiz"
+}
+
+// Fetch returns the Tiz races, using the shared 24h cache
+func (TizSource) Fetch(ctx context.Context) ([]types.TizRace, error) {
+	return GetTizRaces(ctx)
+}
 
-// GetTizRaces fetches race data from Tiz-cycling endpoint with 24h caching
-func GetTizRaces() ([]types.TizRace, error) {
-	// Check cache
-	raceCache.RLock()
-	if !raceCache.LastFetch.IsZero() && time.Since(raceCache.LastFetch) < 24*time.Hour {
-		logger.Log.Info().Msg("Returning cached Tiz race data")
-		races := make([]types.TizRace, len(raceCache.Races))
-		copy(races, raceCache.Races)
-		raceCache.RUnlock()
-		return races, nil
+// Watch keeps the Tiz snapshot fresh in the background until ctx is cancelled
+func (TizSource) Watch(ctx context.Context) {
+	refreshLoop(ctx)
+}
+
+// Warning reports when the Tiz races are served from the last known good snapshot
+func (TizSource) Warning() string {
+	status := GetCacheStatus()
+	if status.LastError == nil || status.LastFetch.IsZero() {
+		return ""
 	}
-	raceCache.RUnlock()
+	return fmt.Sprintf("upstream unavailable, serving schedule fetched at %s", status.LastFetch.UTC().Format(time.RFC3339))
+}
 
-	logger.Log.Info().Msg("Fetching Tiz race data (cache miss or expired)")
+// tizResponse is the outcome of a single upstream fetch
+type tizResponse struct {
+	Races        []types.TizRace
+	RawHTML      string
+	ETag         string
+	LastModified string
+	NotModified  bool // The upstream answered 304, the cached races are still valid
+}
 
-	req, err := http.NewRequest("GET", tizURL, nil)
+// fetchTizRaces downloads and parses the Tiz-cycling schedule, bypassing the
+// cache. The etag and lastModified validators of the cached copy, if any, make
+// the request conditional.
+func fetchTizRaces(ctx context.Context, etag, lastModified string) (tizResponse, error) {
+	logger.Log.Info().Msg("Fetching Tiz race data")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", tizURL, nil)
 	if err != nil {
 		logger.Log.Error().Err(err).Msg("Failed to create request")
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return tizResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set headers from curl command
+	// Identify ourselves honestly and only download the page when it changed
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	// Removed Accept-Encoding to let http.Transport handle it
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 	req.Header.Set("Referer", "https://cyclingtiz.live/")
-	req.Header.Set("Sec-Fetch-Dest", "empty")
-	req.Header.Set("Sec-Fetch-Mode", "cors")
-	req.Header.Set("Sec-Fetch-Site", "same-origin")
-	req.Header.Set("Connection", "keep-alive")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: fetchTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		logger.Log.Error().Err(err).Msg("Request failed")
-		return nil, fmt.Errorf("request failed: %w", err)
+		return tizResponse{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		logger.Log.Info().Msg("Tiz schedule not modified")
+		return tizResponse{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		logger.Log.Error().Int("statusCode", resp.StatusCode).Msg("Non-200 response")
-		return nil, fmt.Errorf("received non-200 response: %d", resp.StatusCode)
+		return tizResponse{}, fmt.Errorf("received non-200 response: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logger.Log.Error().Err(err).Msg("Failed to read response body")
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return tizResponse{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	logger.Log.Info().Int("bodyLength", len(body)).Msg("Response body length")
@@ -88,33 +131,54 @@ func GetTizRaces() ([]types.TizRace, error) {
 	}
 
 	htmlContent := string(body)
-	races, err := parseTizRaces(htmlContent)
+	races, report, err := parseTizRaces(htmlContent, time.Now())
 	if err != nil {
 		logger.Log.Error().Err(err).Msg("Failed to parse races")
-		return nil, fmt.Errorf("failed to parse races: %w", err)
-	}
-
-	logger.Log.Info().Int("raceCount", len(races)).Msg("Tiz races fetched successfully")
-
-	// Update cache
-	raceCache.Lock()
-	raceCache.Races = races
-	raceCache.LastFetch = time.Now()
-	raceCache.Unlock()
-
-	return races, nil
+		return tizResponse{}, fmt.Errorf("failed to parse races: %w", err)
+	}
+	// Published even for an empty schedule, which is when it matters most
+	publishParseReport(report)
+
+	// An empty schedule is a broken page, not an empty calendar
+	if len(races) == 0 {
+		logger.Log.Error().
+			Int("bodyLength", len(body)).
+			Int("entriesSeen", report.EntriesSeen).
+			Int("entriesSkipped", len(report.Skipped)).
+			Msg("Parsed zero races from upstream")
+		return tizResponse{}, errNoRaces
+	}
+
+	logger.Log.Info().
+		Int("raceCount", len(races)).
+		Int("entriesSkipped", len(report.Skipped)).
+		Int("fieldFailures", len(report.FieldFailures)).
+		Msg("Tiz races fetched successfully")
+
+	return tizResponse{
+		Races:        races,
+		RawHTML:      htmlContent,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
 }
 
-// parseTizRaces parses HTML content and extracts race information
-func parseTizRaces(htmlContent string) ([]types.TizRace, error) {
+// parseTizRaces parses HTML content and extracts race information, along
+// with a report of the entries and fields it could not read. fetchedAt is the
+// reference used to infer the year of dates until the TODAY header, which
+// then becomes the reference.
+func parseTizRaces(htmlContent string, fetchedAt time.Time) ([]types.TizRace, ParseReport, error) {
+	report := ParseReport{ParsedAt: fetchedAt}
+
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, report, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	var races []types.TizRace
 	var currentSection string
 	var todayDate string
+	ref := fetchedAt
 
 	// Helper functions for HTML traversall LI elements
 	var findAllLi func(*html.Node) []*html.Node
@@ -131,6 +195,7 @@ func parseTizRaces(htmlContent string) ([]types.TizRace, error) {
 
 	// Find all <li> elements
 	allLis := findAllLi(doc)
+	report.EntriesSeen = len(allLis)
 	logger.Log.Info().Int("totalLisFound", len(allLis)).Msg("Found LI elements in document")
 
 	for _, li := range allLis {
@@ -139,18 +204,22 @@ func parseTizRaces(htmlContent string) ([]types.TizRace, error) {
 		// Check for section headers
 		if strings.Contains(liText, "TODAY") || strings.Contains(liText, "TOMORROW") || strings.Contains(liText, "UPCOMING") {
 			logger.Log.Debug().Str("section", liText).Msg("Found section header")
+			report.Sections++
 			if strings.Contains(liText, "TODAY") {
-				currentSection = parseDateFromHeader(liText)
+				currentSection = parseDateFromHeader(liText, fetchedAt)
 				todayDate = currentSection
+				if today, err := dates.Parse(todayDate); err == nil {
+					ref = today
+				}
 			} else if strings.Contains(liText, "TOMORROW") {
-				date := parseDateFromHeader(liText)
+				date := parseDateFromHeader(liText, ref)
 				if date != "" {
 					currentSection = date
 				} else if todayDate != "" {
 					// Calculate tomorrow from today
-					t, err := time.Parse("2006-01-02", todayDate)
+					t, err := dates.Parse(todayDate)
 					if err == nil {
-						currentSection = t.AddDate(0, 0, 1).Format("2006-01-02")
+						currentSection = dates.Format(t.AddDate(0, 0, 1))
 					} else {
 						currentSection = "TOMORROW"
 					}
@@ -163,42 +232,52 @@ func parseTizRaces(htmlContent string) ([]types.TizRace, error) {
 			continue
 		}
 
-		// Check if this <li> has an image (flag) - indicates a race entry
+		// Check if this <li> has a flag - indicates a race entry. Other images
+		// illustrate the notes of the schedule (a clock next to "UTC").
 		img := findNode(li, isImgElement)
-		if img == nil {
+		if img == nil || !flagFilePattern.MatchString(getAttribute(img, "src")) {
 			// Only log failure if we think it might be a race (has some length)
 			if len(liText) > 20 {
 				logger.Log.Debug().Str("text", liText).Msg("No image found in LI, skipping")
 			}
+			report.skip(SkipNoFlag, currentSection, liText)
 			continue
 		}
 
 		logger.Log.Debug().Str("section", currentSection).Msg("Attempting to parse race from LI")
 
-		logger.Log.Debug().Str("section", currentSection).Msg("Attempting to parse race from LI")
-
 		// Parse race from this <li>
-		race, err := parseRaceFromLi(li, currentSection)
+		race, err := parseRaceFromLi(li, currentSection, ref)
 		if err != nil {
 			logger.Log.Debug().Err(err).Msg("Failed to parse race from li")
+			report.skip(SkipParseError, currentSection, liText)
 			continue
 		}
 
-		if race.Name != "" {
+		switch {
+		case race.Name == "" || isZoneName(race.Name):
+			logger.Log.Warn().Str("text", liText).Msg("Parsed race but name is empty")
+			report.skip(SkipEmptyName, currentSection, liText)
+		case race.DatePrecision == types.DatePrecisionUnknown:
+			// Reported, the calendar cannot place it
+			logger.Log.Warn().Str("name", race.Name).Str("text", liText).Msg("Race has no resolvable date")
+			report.skip(SkipNoDate, currentSection, liText)
+		default:
 			logger.Log.Debug().Str("name", race.Name).Msg("Successfully parsed race")
+			report.checkFields(race, liText)
 			races = append(races, race)
-		} else {
-			logger.Log.Warn().Str("text", liText).Msg("Parsed race but name is empty")
 		}
 	}
 
+	report.RacesParsed = len(races)
 	logger.Log.Info().Int("racesFound", len(races)).Msg("Finished parsing Tiz races")
 
-	return races, nil
+	return races, report, nil
 }
 
-// parseRaceFromLi extracts race data from a single <li> element
-func parseRaceFromLi(li *html.Node, sectionDate string) (types.TizRace, error) {
+// parseRaceFromLi extracts race data from a single <li> element. Dates
+// without a year are resolved relative to ref (see dates.Resolve).
+func parseRaceFromLi(li *html.Node, sectionDate string, ref time.Time) (types.TizRace, error) {
 	race := types.TizRace{}
 
 	// Extract flag and country
@@ -208,6 +287,9 @@ func parseRaceFromLi(li *html.Node, sectionDate string) (types.TizRace, error) {
 		if src != "" {
 			race.Country = extractCountryFromFlag(src)
 			race.CountryFlag = src
+			if country, ok := countries.Lookup(race.Country); ok {
+				race.CountryName = country.Name
+			}
 		}
 	}
 
@@ -215,15 +297,17 @@ func parseRaceFromLi(li *html.Node, sectionDate string) (types.TizRace, error) {
 	text, _ := extractText(li)
 
 	// Parse dates
-	race.StartDate, race.EndDate = parseDatesFromText(text)
+	race.StartDate, race.EndDate, race.DatePrecision = parseDatesFromText(text, ref)
 
 	// Fallback to section date if specific date not found
-	if race.StartDate == "" && sectionDate != "" && sectionDate != "UPCOMING" {
+	if race.StartDate == "" && !dateTBCPattern.MatchString(text) && sectionDate != "" && sectionDate != "UPCOMING" && sectionDate != "TOMORROW" {
 		race.StartDate = sectionDate
 		race.EndDate = sectionDate
+		race.DatePrecision = types.DatePrecisionDay
 	}
 
-	race.AllDay = strings.Contains(text, "times TBA") || strings.Contains(text, "time TBA")
+	race.AllDay = strings.Contains(text, "times TBA") || strings.Contains(text, "time TBA") ||
+		race.DatePrecision == types.DatePrecisionMonth
 
 	// Parse categories
 	race.Categories = extractCategories(text)
@@ -239,37 +323,37 @@ func parseRaceFromLi(li *html.Node, sectionDate string) (types.TizRace, error) {
 		race.StreamType = "RECORDED"
 	}
 
-	// Parse stream links
-	race.StreamLinks = extractLinks(li)
-	if len(race.StreamLinks) > 0 {
-		// Determine language from text
-		if strings.Contains(text, "(English or Spanish)") {
-			race.StreamLang = "English or Spanish"
-		} else if strings.Contains(text, "(Spanish)") {
-			race.StreamLang = "Spanish"
-		} else if strings.Contains(text, "(Slovenian)") {
-			race.StreamLang = "Slovenian"
-		} else if strings.Contains(text, "(Flemish)") {
-			race.StreamLang = "Flemish"
-		} else if strings.Contains(text, "(Arabic)") {
-			race.StreamLang = "Arabic"
+	// Parse the status marked by the feed, notes may mention other editions
+	race.Status, race.StatusNote = parseStatus(extractTextWithoutNotes(li), race.StreamType)
+
+	// Parse stream links and the commentary of the first broadcast
+	race.Links = extractLinks(li)
+	for _, link := range race.Links {
+		if link.Kind != types.LinkKindStream {
+			race.StreamLinks = append(race.StreamLinks, link.URL)
+		}
+		if race.StreamLang == "" && link.Watchable() {
+			race.StreamLang = link.Language
 		}
 	}
 
 	// Parse notes
-	race.Notes = extractNotes(text)
+	race.Notes = extractNotes(li)
+
+	// Parse name and stage
+	race.Name, race.Stage = parseNameAndStage(text)
+	race.Discipline = classifyDiscipline(race)
 
-	// Parse times
+	// Parse times, then pin day specific slots to dates of the race
 	race.Times = extractTimes(text)
+	resolveSlotDates(&race, ref)
+	resolveSlotTimes(&race)
 
 	// Calculate duration from times if not already set
 	if race.Duration == "" && len(race.Times) > 0 {
-		race.Duration = calculateDurationFromTimes(race.Times)
+		race.Duration = calculateDurationFromTimes(race.Times, race.StartDate)
 	}
 
-	// Parse name and stage
-	race.Name, race.Stage = parseNameAndStage(text)
-
 	// Final cleanup and defaults
 	if race.EndDate == "" && race.StartDate != "" {
 		race.EndDate = race.StartDate
@@ -278,8 +362,31 @@ func parseRaceFromLi(li *html.Node, sectionDate string) (types.TizRace, error) {
 	return race, nil
 }
 
-// parseDateFromHeader parses a date from section header like "TODAY Wednesday 4th February"
-func parseDateFromHeader(header string) string {
+// parseStatus tells the status of a race from the marks of the feed. Races
+// cancelled or postponed are CANCELLED and races only possibly or probably
+// broadcast TENTATIVE. A neutralised race is still held: it stays confirmed
+// and the mark is kept as a note.
+func parseStatus(text, streamType string) (status, note string) {
+	if match := statusPattern.FindStringSubmatch(text); match != nil {
+		switch mark := strings.ToLower(match[1]); {
+		case strings.HasPrefix(mark, "cancel"):
+			return types.StatusCancelled, "Cancelled"
+		case mark == "postponed":
+			return types.StatusCancelled, "Postponed"
+		default:
+			note = "Neutralised"
+		}
+	}
+
+	if streamType == "POSSIBLE LIVE" || streamType == "PROBABLE LIVE" {
+		return types.StatusTentative, note
+	}
+	return types.StatusConfirmed, note
+}
+
+// parseDateFromHeader parses a date from section header like "TODAY Wednesday 4th February",
+// resolving the year relative to ref
+func parseDateFromHeader(header string, ref time.Time) string {
 	// Extract "Wednesday 4th February"
 	// Replace known prefixes/suffixes with empty string
 	header = strings.Replace(header, "TODAY", "", -1)
@@ -309,93 +416,165 @@ func parseDateFromHeader(header string) string {
 			return ""
 		}
 
-		// Construct date for current year
-		// Note: This assumes current year. For end of year/beginning of year logic we might need improvement
-		currentYear := time.Now().Year()
-		fullDate := time.Date(currentYear, date.Month(), day, 0, 0, 0, 0, time.UTC)
+		// The header has no year, resolve it relative to the reference
+		fullDate, ok := dates.Resolve(date.Month(), day, ref)
+		if !ok {
+			logger.Log.Debug().Str("header", header).Msg("Invalid day in header")
+			return ""
+		}
 
-		logger.Log.Debug().Str("header", header).Str("parsed", fullDate.Format("2006-01-02")).Msg("Parsed date from header")
-		return fullDate.Format("2006-01-02")
+		logger.Log.Debug().Str("header", header).Str("parsed", dates.Format(fullDate)).Msg("Parsed date from header")
+		return dates.Format(fullDate)
 	}
 
 	logger.Log.Debug().Str("header", header).Str("parts_count", fmt.Sprintf("%d", len(parts))).Msg("Failed to parse date from header (parts < 3)")
 	return ""
 }
 
-// extractCountryFromFlag extracts country code from flag URL
+// extractCountryFromFlag maps the file name of a flag image to the ISO 3166
+// alpha-2 code of its country, or "" when it names no known country
 func extractCountryFromFlag(src string) string {
-	// Pattern: https://tiz-cycling.io/flags/B_be.png -> BE
-	// Pattern: https://flagpedia.net/data/flags/w580/fr.png -> FR
-	re := regexp.MustCompile(`([A-Z_]+|[a-z]{2})\.(png|webp)`)
-	matches := re.FindStringSubmatch(src)
-	if len(matches) < 2 {
+	match := flagFilePattern.FindStringSubmatch(src)
+	if match == nil {
 		return ""
 	}
 
-	code := matches[1]
-	// Handle special cases like B_be, E_es, UAE_ae, SLO_si
-	if strings.Contains(code, "_") {
-		parts := strings.Split(code, "_")
-		code = parts[len(parts)-1] // Take last part
+	// Tiz flags prefix the code with a national abbreviation (B_be, UAE_ae, SLO_si)
+	parts := strings.Split(match[1], "_")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if country, ok := countries.Lookup(parts[i]); ok {
+			return country.Alpha2
+		}
 	}
-
-	return strings.ToUpper(code[:2])
+	return ""
 }
 
-// parseDatesFromText extracts start and end dates from text
-func parseDatesFromText(text string) (startDate, endDate string) {
-	startDate = ""
-	endDate = ""
+// Date expressions used by the feed. Years are never printed.
+const (
+	weekdayExpr = `(?:(?:Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday|Mon|Tue|Wed|Thu|Fri|Sat|Sun)\.?\s+)?`
+	dayExpr     = `(\d{1,2})(?:st|nd|rd|th)?`
+	monthExpr   = `(January|February|March|April|May|June|July|August|September|October|November|December|Jan|Feb|Mar|Apr|Jun|Jul|Aug|Sept|Sep|Oct|Nov|Dec)\b`
+	dashExpr    = `\s*[-–]\s*`
+)
 
-	// Pattern for explicit date: "Friday 6th February"
-	datePattern := regexp.MustCompile(`(?:Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)\s+(\d+)(?:st|nd|rd|th)\s+(January|February|March|April|May|June|July|August|September|October|November|December)`)
-	dateMatch := datePattern.FindString(text)
+var (
+	// "28th February - 2nd March", only at the start of an entry
+	crossMonthRangePattern = regexp.MustCompile(`^` + weekdayExpr + dayExpr + `\s+` + monthExpr + dashExpr + weekdayExpr + dayExpr + `\s+` + monthExpr)
+	// "6th-8th February", "Friday 6th - Sunday 8th February", only at the start of an entry
+	dayRangePattern = regexp.MustCompile(`^` + weekdayExpr + dayExpr + dashExpr + weekdayExpr + dayExpr + `\s+` + monthExpr)
+	// "Friday 6th February" anywhere, "6th February" at the start of an entry
+	singleDatePattern = regexp.MustCompile(`(?:Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)\s+(\d+)(?:st|nd|rd|th)\s+` + monthExpr + `|` + `^(\d{1,2})(?:st|nd|rd|th)\s+` + monthExpr)
+	// "February - Race name", only at the start of an entry
+	monthOnlyPattern = regexp.MustCompile(`^(?:(?:early|mid|late)\s+)?` + monthExpr + `(?:\s+\d{4})?` + dashExpr)
+	// "TBC - Race name", "Date TBA - Race name", "dates TBC"
+	dateTBCPattern = regexp.MustCompile(`(?i)^(?:dates?\s+)?(?:TBC|TBA|TBD)\b|\bdates?\s+(?:TBC|TBA|TBD)\b`)
+	// "for 3 days"
+	forDaysPattern = regexp.MustCompile(`for\s+(\d+)\s+days?`)
+	// Any date expression leading an entry, stripped from the race name
+	datePrefixPattern = regexp.MustCompile(`^(?:` +
+		weekdayExpr + dayExpr + `\s+` + monthExpr + dashExpr + weekdayExpr + dayExpr + `\s+` + monthExpr + `|` +
+		weekdayExpr + dayExpr + dashExpr + weekdayExpr + dayExpr + `\s+` + monthExpr + `|` +
+		weekdayExpr + dayExpr + `\s+` + monthExpr + `|` +
+		`(?:(?:early|mid|late)\s+)?` + monthExpr + `(?:\s+\d{4})?` + `|` +
+		`(?i:(?:dates?\s+)?(?:TBC|TBA|TBD))` +
+		`)(?:\s+for\s+\d+\s+days?)?` + dashExpr)
+)
 
-	if dateMatch != "" {
-		year := time.Now().Year()
-		startDate = parseDate(dateMatch, year)
+// parseDatesFromText extracts start and end dates from text, resolving the
+// year relative to ref. precision is types.DatePrecisionDay or
+// types.DatePrecisionMonth when a date was found, and
+// types.DatePrecisionUnknown when the entry has no usable date.
+func parseDatesFromText(text string, ref time.Time) (startDate, endDate, precision string) {
+	text = strings.TrimSpace(text)
+
+	if dateTBCPattern.MatchString(text) {
+		return "", "", types.DatePrecisionUnknown
 	}
 
-	// Pattern for multi-day: "for 3 days", "for 5 days"
-	durationPattern := regexp.MustCompile(`for\s+(\d+)\s+days?`)
-	durationSubmatch := durationPattern.FindStringSubmatch(text)
+	// Ranges spanning two months: "28th February - 2nd March"
+	if m := crossMonthRangePattern.FindStringSubmatch(text); m != nil {
+		start, okStart := resolveDayMonth(m[1], m[2], ref)
+		end, okEnd := resolveDayMonth(m[3], m[4], start)
+		if okStart && okEnd {
+			return dates.Format(start), dates.Format(rollForward(start, end)), types.DatePrecisionDay
+		}
+	}
 
-	if len(durationSubmatch) > 1 && startDate != "" {
-		durationDays, _ := strconv.Atoi(durationSubmatch[1])
-		if durationDays > 0 {
-			startTime, _ := time.Parse("2006-01-02", startDate)
-			endTime := startTime.AddDate(0, 0, durationDays)
-			endDate = endTime.Format("2006-01-02")
+	// Ranges within a month: "6th-8th February"
+	if m := dayRangePattern.FindStringSubmatch(text); m != nil {
+		end, okEnd := resolveDayMonth(m[2], m[3], ref)
+		start, okStart := resolveDayMonth(m[1], m[3], end)
+		if okStart && okEnd {
+			return dates.Format(start), dates.Format(rollForward(start, end)), types.DatePrecisionDay
+		}
+	}
+
+	// Single day, optionally lasting several days: "Friday 6th February for 3 days"
+	if m := singleDatePattern.FindStringSubmatch(text); m != nil {
+		day, month := m[1], m[2]
+		if day == "" {
+			day, month = m[3], m[4]
+		}
+		if start, ok := resolveDayMonth(day, month, ref); ok {
+			startDate = dates.Format(start)
+
+			if days := forDaysPattern.FindStringSubmatch(text); len(days) > 1 {
+				// The start date is the first of the N days
+				durationDays, _ := strconv.Atoi(days[1])
+				if durationDays > 0 {
+					endDate = dates.Format(start.AddDate(0, 0, durationDays-1))
+				}
+			}
+			return startDate, endDate, types.DatePrecisionDay
 		}
 	}
 
-	return startDate, endDate
+	// Month only: "February - Race name"
+	if m := monthOnlyPattern.FindStringSubmatch(text); m != nil {
+		if first, ok := resolveDayMonth("1", m[1], ref); ok {
+			last := first.AddDate(0, 1, -1)
+			return dates.Format(first), dates.Format(last), types.DatePrecisionMonth
+		}
+	}
+
+	return "", "", types.DatePrecisionUnknown
 }
 
-// parseDate converts "Friday 6th February" to "2026-02-06"
-func parseDate(dateStr string, year int) string {
-	monthMap := map[string]int{
-		"January": 1, "February": 2, "March": 3, "April": 4,
-		"May": 5, "June": 6, "July": 7,
-		"August": 8, "September": 9, "October": 10,
-		"November": 11, "December": 12,
+// resolveDayMonth resolves a day and a (possibly abbreviated) month name to
+// its date relative to ref
+func resolveDayMonth(dayStr, monthName string, ref time.Time) (time.Time, bool) {
+	day, err := strconv.Atoi(dayStr)
+	if err != nil {
+		return time.Time{}, false
 	}
 
-	re := regexp.MustCompile(`(\d+)(?:st|nd|rd|th)\s+(\w+)`)
-	matches := re.FindStringSubmatch(dateStr)
-	if len(matches) < 3 {
-		return ""
+	month, ok := parseMonth(monthName)
+	if !ok {
+		return time.Time{}, false
 	}
 
-	day, _ := strconv.Atoi(matches[1])
-	monthName := matches[2]
+	return dates.Resolve(month, day, ref)
+}
 
-	month, ok := monthMap[monthName]
-	if !ok {
-		return ""
+// rollForward moves end after start when a range crosses New Year
+func rollForward(start, end time.Time) time.Time {
+	for end.Before(start) {
+		end = end.AddDate(1, 0, 0)
 	}
+	return end
+}
 
-	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+// parseMonth parses a full or abbreviated English month name
+func parseMonth(name string) (time.Month, bool) {
+	if name == "Sept" {
+		name = "Sep"
+	}
+	for _, layout := range []string{"January", "Jan"} {
+		if date, err := time.Parse(layout, name); err == nil {
+			return date.Month(), true
+		}
+	}
+	return 0, false
 }
 
 // extractCategories extracts race categories from text
@@ -406,28 +585,13 @@ func extractCategories(text string) []string {
 	re := regexp.MustCompile(`\(([^)]+)\)`)
 	matches := re.FindAllStringSubmatch(text, -1)
 
-	validCategories := map[string]bool{
-		"WE":          true,
-		"ME":          true,
-		"track":       true,
-		"MTB":         true,
-		"NC":          true,
-		"JR":          true,
-		"WC":          true,
-		"Elite":       true,
-		"Women Elite": true,
-		"Men Elite":   true,
-		"Women":       true,
-		"Men":         true,
-	}
-
 	for _, match := range matches {
 		if len(match) > 1 {
 			// Split by comma
 			parts := strings.Split(match[1], ",")
 			for _, part := range parts {
 				cat := strings.TrimSpace(part)
-				if validCategories[cat] {
+				if types.IsTizCategory(cat) {
 					if !containsString(categories, cat) {
 						categories = append(categories, cat)
 					}
@@ -456,83 +620,75 @@ func containsString(slice []string, item string) bool {
 	return false
 }
 
-// extractLinks extracts all href links from <li> element
-func extractLinks(li *html.Node) []string {
-	var links []string
-
-	for a := findNode(li, isAElement); a != nil; a = findNode(a.NextSibling, isAElement) {
-		href := getAttribute(a, "href")
-		if href != "" && !strings.HasPrefix(href, "javascript:") {
-			// Clean URL if needed
-			if strings.HasPrefix(href, "//") {
-				href = "https:" + href
+// extractLinks extracts the labelled links of a <li> element: the Tiz
+// stream page, which the feed names without linking, and every anchor
+func extractLinks(li *html.Node) []types.TizLink {
+	var links []types.TizLink
+
+	for _, n := range findNodes(li, isLinkElement) {
+		label, _ := extractText(n)
+		link := types.TizLink{Label: label}
+		// Parentheses that name no language, such as the "(language)" placeholder, are not commentary
+		if written := linkLanguage(n); written != "" {
+			if link.Languages = languages.Parse(written); link.Languages != nil {
+				link.Language = written
 			}
-			links = append(links, href)
 		}
-	}
 
-	return links
-}
-
-// extractNotes extracts text from <em> tags
-func extractNotes(text string) string {
-	re := regexp.MustCompile(`<em>([^<]+)</em>`)
-	matches := re.FindAllStringSubmatch(text, -1)
+		if n.Data != "a" {
+			link.URL, link.Kind = tizStreamPageURL, types.LinkKindStream
+			links = append(links, link)
+			continue
+		}
 
-	var notes []string
-	for _, match := range matches {
-		if len(match) > 1 {
-			note := strings.TrimSpace(match[1])
-			if note != "" {
-				notes = append(notes, note)
-			}
+		link.URL = getAttribute(n, "href")
+		if link.URL == "" || strings.HasPrefix(link.URL, "javascript:") {
+			continue
+		}
+		// Clean URL if needed
+		if strings.HasPrefix(link.URL, "//") {
+			link.URL = "https:" + link.URL
+		}
+		if link.Label == link.URL || strings.HasPrefix(link.Label, "http") {
+			link.Label = ""
+		}
+		link.Kind = types.LinkKindDirect
+		if strings.HasPrefix(strings.ToLower(label), "info") {
+			link.Kind = types.LinkKindInfo
 		}
+		links = append(links, link)
 	}
 
-	return strings.Join(notes, " | ")
+	return links
 }
 
-// extractTimes extracts time slots from text
-func extractTimes(text string) []types.TizTimeSlot {
-	var timeSlots []types.TizTimeSlot
-
-	// Pattern for WE and ME times: "WE 12.40 UTC (60 mins) - ME 14.00 UTC (90 mins)"
-	timePattern := regexp.MustCompile(`(WE|ME)\s+(\d+\.\d+)\s+UTC\s+\(([^)]+)\)`)
-	matches := timePattern.FindAllStringSubmatch(text, -1)
-
-	for _, match := range matches {
-		if len(match) >= 3 {
-			timeSlots = append(timeSlots, types.TizTimeSlot{
-				Category: match[1],
-				Time:     fmt.Sprintf("%s:00 UTC", strings.Replace(match[2], ".", ":", 1)),
-				Duration: match[3],
-			})
-		}
+// linkLanguage returns the commentary language written in parentheses
+// right after a link, as in "<strong><a>Link</a></strong> (Spanish)"
+func linkLanguage(n *html.Node) string {
+	for n.NextSibling == nil && n.Parent != nil && n.Parent.Data != "li" {
+		n = n.Parent
 	}
-
-	// Pattern for single time: "14.45 UTC (90 mins)"
-	singleTimePattern := regexp.MustCompile(`(\d+\.\d+)\s+UTC\s+\(([^)]+)\)`)
-	singleMatch := singleTimePattern.FindStringSubmatch(text)
-
-	if singleMatch != nil && len(singleMatch) >= 2 && len(timeSlots) == 0 {
-		timeSlots = append(timeSlots, types.TizTimeSlot{
-			Category: "",
-			Time:     fmt.Sprintf("%s:00 UTC", strings.Replace(singleMatch[1], ".", ":", 1)),
-			Duration: singleMatch[2],
-		})
+	if n.NextSibling == nil || n.NextSibling.Type != html.TextNode {
+		return ""
 	}
-
-	return timeSlots
+	if match := linkLanguagePattern.FindStringSubmatch(n.NextSibling.Data); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
 }
 
-// calculateDurationFromTimes calculates duration from time slots
-func calculateDurationFromTimes(times []types.TizTimeSlot) string {
-	if len(times) == 0 {
-		return ""
+// extractNotes joins the text of the <em> elements of a <li> element
+func extractNotes(li *html.Node) string {
+	var notes []string
+	for _, em := range findNodes(li, isEmElement) {
+		note, _ := extractText(em)
+		note = strings.Join(strings.Fields(note), " ")
+		if note != "" {
+			notes = append(notes, note)
+		}
 	}
 
-	// Use duration from first time slot for display
-	return times[0].Duration
+	return strings.Join(notes, " | ")
 }
 
 // parseNameAndStage extracts race name and stage information
@@ -560,12 +716,8 @@ func parseNameAndStage(text string) (name, stage string) {
 	re = regexp.MustCompile(`\s*-\s*<strong><a[^>]+>Link</a></strong>\s*\([^)]*\)\s*[^-]*`)
 	name = re.ReplaceAllString(name, "")
 
-	// Remove date prefixes
-	re = regexp.MustCompile(`^(Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)\s+\d+(?:st|nd|rd|th)\s+\w+\s+for\s+\d+\s+days?\s*-\s*`)
-	name = re.ReplaceAllString(name, "")
-
-	re = regexp.MustCompile(`^(Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)\s+\d+(?:st|nd|rd|th)\s+\w+\s*-\s*`)
-	name = re.ReplaceAllString(name, "")
+	// Remove date prefixes (single days, ranges, month only, TBC)
+	name = datePrefixPattern.ReplaceAllString(strings.TrimSpace(name), "")
 
 	// Remove Info links at the end
 	re = regexp.MustCompile(`\s*-\s*Info\s*$`)
@@ -574,23 +726,31 @@ func parseNameAndStage(text string) (name, stage string) {
 	re = regexp.MustCompile(`\s*-\s*<strong><a[^>]+>Info</a></strong>\s*$`)
 	name = re.ReplaceAllString(name, "")
 
-	// Remove time info with Category prefix (e.g. - WE 12.40 UTC ...)
-	re = regexp.MustCompile(`\s*-\s*(WE|ME|track|MTB)\s+\d+(?:[\.:]\d+)?\s+UTC.*`)
+	// Remove time info with a label prefix (e.g. - WE 12.40 UTC ..., - Men U23 10.00 CEST ...)
+	re = regexp.MustCompile(`\s*-\s*(?:[A-Za-z0-9]+(?:-\d+)?:?\s+){1,4}\d{1,2}(?:[\.:h]\d{2}\s*(?:` + zoneExpr() + `)|[:h]\d{2}\b).*`)
 	name = re.ReplaceAllString(name, "")
 
-	// Remove time info (e.g. - 12.40 UTC ...)
-	re = regexp.MustCompile(`\s*-\s*\d+(?:[\.:]\d+)?\s+UTC.*`)
+	// Remove time info (e.g. - 12.40 UTC ..., - 14:00 ...)
+	re = regexp.MustCompile(`\s*-\s*\d+(?:(?:[\.:h]\d+)?\s*(?:` + zoneExpr() + `)|[:h]\d{2}\b).*`)
 	name = re.ReplaceAllString(name, "")
 
 	// Remove categories (WE, ME, etc.) anywhere in the name if in parentheses
 	// We matched (WE, ME), (WE), (ME), etc.
-	re = regexp.MustCompile(`\s*\((?:WE|ME|track|MTB|NC|JR|WC|Elite|Women Elite|Men Elite|Women|Men)(?:,\s*(?:WE|ME|track|MTB|NC|JR|WC|Elite|Women Elite|Men Elite|Women|Men))*\)\s*`)
+	re = regexp.MustCompile(`\s*\((?:WE|ME|track|MTB|NC|JR|U23|WC|Elite|Women Elite|Men Elite|Women|Men)(?:,\s*(?:WE|ME|track|MTB|NC|JR|U23|WC|Elite|Women Elite|Men Elite|Women|Men))*\)\s*`)
 	name = re.ReplaceAllString(name, "")
 
 	// Remove TBA mentions
 	re = regexp.MustCompile(`\s*-\s*times?\s+TBA\s*`)
 	name = re.ReplaceAllString(name, "")
 
+	// Remove status marks, the race keeps its UID when it is cancelled
+	name = statusPattern.ReplaceAllStringFunc(name, func(mark string) string {
+		if strings.HasSuffix(mark, "-") {
+			return " -"
+		}
+		return ""
+	})
+
 	// Remove trailing dashes and spaces
 	name = strings.Trim(name, "- ")
 	name = strings.TrimSpace(name)
@@ -626,6 +786,22 @@ func isAElement(n *html.Node) bool {
 	return n.Type == html.ElementNode && n.Data == "a"
 }
 
+func isEmElement(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "em"
+}
+
+// isLinkElement matches anchors and the unlinked "Stream Page" label
+func isLinkElement(n *html.Node) bool {
+	if isAElement(n) {
+		return true
+	}
+	if n.Type != html.ElementNode || n.Data != "strong" || findNode(n, isAElement) != nil {
+		return false
+	}
+	text, _ := extractText(n)
+	return strings.EqualFold(text, "Stream Page")
+}
+
 func getAttribute(n *html.Node, attrName string) string {
 	for _, attr := range n.Attr {
 		if attr.Key == attrName {
@@ -653,6 +829,22 @@ func extractText(n *html.Node) (text, link string) {
 	return strings.TrimSpace(buf), link
 }
 
+// extractTextWithoutNotes returns the text of n without its <em> notes
+func extractTextWithoutNotes(n *html.Node) string {
+	if isEmElement(n) {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(extractTextWithoutNotes(c))
+	}
+	return b.String()
+}
+
 func href(n *html.Node) string {
 	if n.Type == html.ElementNode && n.Data == "a" {
 		for _, attr := range n.Attr {
@@ -665,6 +857,23 @@ func href(n *html.Node) string {
 	return ""
 }
 
+// findNodes returns every node of the tree rooted at n matching predicate,
+// in document order
+func findNodes(n *html.Node, predicate func(*html.Node) bool) []*html.Node {
+	if n == nil {
+		return nil
+	}
+	var found []*html.Node
+	if predicate(n) {
+		found = append(found, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findNodes(c, predicate)...)
+	}
+
+	return found
+}
+
 func findNode(n *html.Node, predicate func(*html.Node) bool) *html.Node {
 	if n == nil {
 		return nil
@@ -0,0 +1,307 @@
+package request
+
+import (
+	"cpe/calendar/logger"
+	"cpe/calendar/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// uidDomain makes the UIDs of the calendar globally unique (RFC 5545 3.8.4.7)
+	uidDomain    = "cycling.for-loop.fr"
+	uidsVersion  = 2
+	uidsFilename = "uids.json"
+	// maxSlugLength bounds the name part of a UID
+	maxSlugLength = 60
+)
+
+var (
+	uids = struct {
+		sync.Mutex
+		aliases map[string]string      // Identity of a race to the UID it was first given
+		known   map[string]uidIdentity // UID to the identity it was created from
+	}{aliases: map[string]string{}, known: map[string]uidIdentity{}}
+
+	// accentFolder spells the accented letters of race names in ASCII
+	accentFolder = strings.NewReplacer(
+		"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ą", "a", "æ", "ae",
+		"ç", "c", "ć", "c", "č", "c", "ď", "d", "đ", "d",
+		"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ę", "e", "ě", "e",
+		"ì", "i", "í", "i", "î", "i", "ï", "i", "ı", "i", "ł", "l", "ľ", "l",
+		"ñ", "n", "ń", "n", "ň", "n", "ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ő", "o", "œ", "oe",
+		"ř", "r", "ś", "s", "š", "s", "ş", "s", "ß", "ss", "ť", "t", "ţ", "t",
+		"ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u",
+		"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+	)
+)
+
+// uidIdentity is the normalised identity of a race a UID is derived from
+type uidIdentity struct {
+	Slug       string    `json:"slug"`
+	StartDate  string    `json:"start_date"`
+	Discipline string    `json:"discipline,omitempty"`
+	Country    string    `json:"country,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// uidsFile is the on-disk representation of the UID table
+type uidsFile struct {
+	Version int                    `json:"version"`
+	Aliases map[string]string      `json:"aliases"`
+	Known   map[string]uidIdentity `json:"known"`
+}
+
+// LoadUIDs restores the UID table from the data directory. A missing table
+// is not an error.
+func LoadUIDs() error {
+	if dataDir == "" {
+		return nil
+	}
+
+	path := filepath.Join(dataDir, uidsFilename)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read UID table: %w", err)
+	}
+
+	var file uidsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("failed to decode UID table: %w", err)
+	}
+	if file.Version == 1 {
+		// Version 1 identities held the categories instead of the discipline,
+		// the UIDs derived from them are not served any more
+		logger.Log.Info().Str("path", path).Msg("Discarded UID table of version 1")
+		return nil
+	}
+	if file.Version != uidsVersion {
+		return fmt.Errorf("unsupported UID table version %d (expected %d)", file.Version, uidsVersion)
+	}
+
+	uids.Lock()
+	uids.aliases, uids.known = file.Aliases, file.Known
+	if uids.aliases == nil {
+		uids.aliases = map[string]string{}
+	}
+	if uids.known == nil {
+		uids.known = map[string]uidIdentity{}
+	}
+	uids.Unlock()
+
+	logger.Log.Info().Str("path", path).Int("count", len(file.Known)).Msg("Loaded UID table from disk")
+	return nil
+}
+
+// assignUIDs sets the UID of every race. A race keeps the UID of a race
+// served before with the same start date, discipline and country and a
+// nearly identical name, so fixing a typo in a title or adding a category
+// does not duplicate the event in calendars. Races that already have a UID
+// keep it.
+func assignUIDs(races []types.TizRace, now time.Time) {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	uids.Lock()
+	defer uids.Unlock()
+
+	identities := make([]uidIdentity, len(races))
+	candidates := make([]string, len(races))
+	present := make(map[string]bool, len(races))
+	used := make(map[string]bool, len(races))
+	for i, race := range races {
+		if race.UID != "" {
+			present[race.UID], used[race.UID] = true, true
+			continue
+		}
+		identities[i] = raceIdentity(race)
+		candidates[i] = identities[i].uid()
+		present[candidates[i]] = true
+		if alias, ok := uids.aliases[candidates[i]]; ok {
+			present[alias] = true
+		}
+	}
+
+	dirty := false
+	for i := range races {
+		if races[i].UID != "" {
+			continue
+		}
+		identity, candidate := identities[i], candidates[i]
+		uid, ok := uids.aliases[candidate]
+		if !ok {
+			uid = candidate
+			if _, known := uids.known[candidate]; !known {
+				if previous, found := similarUID(identity, present, used); found {
+					logger.Log.Info().Str("uid", previous).Str("slug", identity.Slug).Msg("Keeping the UID of a renamed race")
+					uid = previous
+					uids.aliases[candidate] = previous
+					dirty = true
+				}
+			}
+		}
+		// Two races with the same identity in one schedule
+		for n := 2; used[uid]; n++ {
+			uid = strings.Replace(candidate, "@", fmt.Sprintf("-%d@", n), 1)
+		}
+		used[uid] = true
+		races[i].UID = uid
+
+		known, ok := uids.known[uid]
+		if !ok {
+			known = identity
+			dirty = true
+		}
+		if !known.LastSeen.Equal(today) {
+			known.LastSeen = today
+			dirty = true
+		}
+		uids.known[uid] = known
+	}
+
+	// Forgotten along with the revisions of the race
+	for uid, identity := range uids.known {
+		if today.Sub(identity.LastSeen) > revisionRetention {
+			delete(uids.known, uid)
+			dirty = true
+		}
+	}
+	for alias, uid := range uids.aliases {
+		if _, ok := uids.known[uid]; !ok {
+			delete(uids.aliases, alias)
+			dirty = true
+		}
+	}
+
+	if !dirty || dataDir == "" {
+		return
+	}
+	content, err := json.Marshal(uidsFile{Version: uidsVersion, Aliases: uids.aliases, Known: uids.known})
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to encode UID table")
+		return
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, uidsFilename), content); err != nil {
+		logger.Log.Error().Err(err).Msg("Failed to persist UID table")
+	}
+}
+
+// similarUID finds the UID of a race no longer in the schedule whose
+// identity only differs from identity by a small edit of the name
+func similarUID(identity uidIdentity, present, used map[string]bool) (string, bool) {
+	var matches []string
+	for uid, known := range uids.known {
+		if present[uid] || used[uid] || known.StartDate != identity.StartDate ||
+			known.Discipline != identity.Discipline || known.Country != identity.Country {
+			continue
+		}
+		if similarSlugs(known.Slug, identity.Slug) {
+			matches = append(matches, uid)
+		}
+	}
+	if len(matches) != 1 {
+		// Several candidates cannot be told apart safely
+		return "", false
+	}
+	return matches[0], true
+}
+
+// similarSlugs tells whether two slugs differ by at most a fifth of their
+// letters, and at most 3
+func similarSlugs(a, b string) bool {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	distance := editDistance(a, b)
+	return distance <= 3 && distance*5 <= longest
+}
+
+// editDistance is the Levenshtein distance between two ASCII strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// raceIdentity normalises the fields identifying a race
+func raceIdentity(race types.TizRace) uidIdentity {
+	return uidIdentity{
+		Slug:       slugify(race.Name + " " + race.Stage),
+		StartDate:  race.StartDate,
+		Discipline: string(race.Discipline),
+		Country:    strings.ToLower(race.Country),
+	}
+}
+
+// uid formats the UID derived from an identity:
+// tour-of-oman-stage-1-20260207-road-om@cycling.for-loop.fr
+func (id uidIdentity) uid() string {
+	parts := []string{id.Slug}
+	if id.StartDate != "" {
+		parts = append(parts, strings.ReplaceAll(id.StartDate, "-", ""))
+	} else {
+		parts = append(parts, "tbc")
+	}
+	if id.Discipline != "" {
+		parts = append(parts, id.Discipline)
+	}
+	if id.Country != "" {
+		parts = append(parts, id.Country)
+	}
+	return strings.Join(parts, "-") + "@" + uidDomain
+}
+
+// slugify lower-cases text, spells accents in ASCII and joins words with dashes
+func slugify(text string) string {
+	text = accentFolder.Replace(strings.ToLower(text))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range text {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if cut := strings.LastIndexByte(slug, '-'); cut > 0 {
+			slug = slug[:cut]
+		}
+	}
+	if slug == "" {
+		return "race"
+	}
+	return slug
+}
@@ -0,0 +1,105 @@
+package request
+
+import (
+	"cpe/calendar/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssignUIDs(t *testing.T) {
+	resetRevisions(t)
+	day := time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+
+	races := []types.TizRace{
+		{Name: "Étoile de Bessèges - Tour du Gard", Stage: "stage 1 (of 5)", StartDate: "2026-02-04", Categories: []string{"ME"}, Country: "FR", Discipline: types.DisciplineRoad},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Categories: []string{"WE"}, Country: "BE", Discipline: types.DisciplineRoad},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2026-02-28", Categories: []string{"ME"}, Country: "BE", Discipline: types.DisciplineRoad},
+		{Name: "Omloop Het Nieuwsblad", StartDate: "2027-02-27", Categories: []string{"ME"}, Country: "BE", Discipline: types.DisciplineRoad},
+		{Name: "Cyclo-cross", StartDate: "2026-02-07", Discipline: types.DisciplineCyclocross},
+		{Name: "Cyclo-cross", StartDate: "2026-02-07"},
+	}
+	assignUIDs(races, day)
+
+	want := []string{
+		"etoile-de-besseges-tour-du-gard-stage-1-of-5-20260204-road-fr@cycling.for-loop.fr",
+		"omloop-het-nieuwsblad-20260228-road-be@cycling.for-loop.fr",
+		"omloop-het-nieuwsblad-20260228-road-be-2@cycling.for-loop.fr",
+		"omloop-het-nieuwsblad-20270227-road-be@cycling.for-loop.fr",
+		"cyclo-cross-20260207-cyclocross@cycling.for-loop.fr",
+		"cyclo-cross-20260207@cycling.for-loop.fr",
+	}
+	for i, race := range races {
+		if race.UID != want[i] {
+			t.Errorf("UID of %s: expected %s, got %s", race.Name, want[i], race.UID)
+		}
+		if strings.ContainsAny(race.UID, " \t,;") {
+			t.Errorf("UID %q is not a valid iCalendar UID", race.UID)
+		}
+	}
+}
+
+func TestAssignUIDsKeepsRenamedRaces(t *testing.T) {
+	resetRevisions(t)
+	day := time.Date(2026, 2, 4, 6, 0, 0, 0, time.UTC)
+
+	typo := []types.TizRace{
+		{Name: "Volta Comunitat Valencianna", Stage: "stage 1 (of 5)", StartDate: "2026-02-04", Categories: []string{"ME"}, Country: "ES"},
+		{Name: "Tour of Oman", StartDate: "2026-02-07", Categories: []string{"ME"}, Country: "OM"},
+	}
+	assignUIDs(typo, day)
+	original := typo[0].UID
+
+	// Restart with the UID table on disk
+	uids.Lock()
+	uids.aliases, uids.known = map[string]string{}, map[string]uidIdentity{}
+	uids.Unlock()
+	if err := LoadUIDs(); err != nil {
+		t.Fatalf("LoadUIDs returned error: %v", err)
+	}
+
+	fixed := []types.TizRace{
+		{Name: "Volta Comunitat Valenciana", Stage: "stage 1 (of 5)", StartDate: "2026-02-04", Categories: []string{"ME"}, Country: "ES"},
+		{Name: "Tour of Qatar", StartDate: "2026-02-07", Categories: []string{"ME"}, Country: "OM"},
+	}
+	assignUIDs(fixed, day.Add(time.Hour))
+	if fixed[0].UID != original {
+		t.Errorf("Expected a fixed typo to keep UID %s, got %s", original, fixed[0].UID)
+	}
+	if fixed[1].UID == typo[1].UID {
+		t.Errorf("Expected a different race to get a new UID, got %s", fixed[1].UID)
+	}
+
+	// A category added by the feed keeps the UID
+	fixed[1].Categories = append(fixed[1].Categories, "WE")
+	uid := fixed[1].UID
+	assignUIDs(fixed, day.Add(90*time.Minute))
+	if fixed[1].UID != uid {
+		t.Errorf("Expected a new category to keep UID %s, got %s", uid, fixed[1].UID)
+	}
+
+	// The alias holds once the original name is gone for good
+	assignUIDs(fixed, day.Add(2*time.Hour))
+	if fixed[0].UID != original {
+		t.Errorf("Expected the alias to be kept, got %s", fixed[0].UID)
+	}
+}
+
+func TestLoadUIDsDiscardsVersion1(t *testing.T) {
+	resetRevisions(t)
+	content := `{"version": 1, "aliases": {}, "known": {"tour-of-oman-20260207-me-om@cycling.for-loop.fr": {"slug": "tour-of-oman", "start_date": "2026-02-07", "discipline": "me", "country": "om"}}}`
+	if err := os.WriteFile(filepath.Join(dataDir, uidsFilename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadUIDs(); err != nil {
+		t.Fatalf("LoadUIDs returned error: %v", err)
+	}
+	uids.Lock()
+	defer uids.Unlock()
+	if len(uids.known) != 0 {
+		t.Errorf("Expected the version 1 table to be discarded, got %v", uids.known)
+	}
+}
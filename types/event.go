@@ -10,16 +10,25 @@ type Event struct {
 	Link          string        `json:"link"`
 	// NEW fields from Tiz endpoint
 	Country       string        `json:"country"`       // ISO 2-letter: BE, FR, ES
+	CountryName   string        `json:"country_name"`  // Belgium, France, Spain
 	CountryFlag   string        `json:"country_flag"`  // Flag URL
+	Status        string        `json:"status"`        // CONFIRMED, TENTATIVE or CANCELLED
+	StatusNote    string        `json:"status_note,omitempty"` // Cancelled, Postponed or Neutralised
 	StreamType    string        `json:"stream_type"`   // LIVE, POSSIBLE LIVE
 	StreamLinks   []string      `json:"stream_links"`  // ALL stream URLs
+	Links         []TizLink     `json:"links"`         // Labelled links: stream page, direct streams, info
 	StreamLang    string        `json:"stream_lang"`   // Language: English, Spanish, Arabic
 	Notes         string        `json:"notes"`         // Additional notes
 	Categories    []string      `json:"categories"`    // [WE, ME, track, MTB]
+	Discipline    Discipline    `json:"discipline"`    // road, cyclocross, track, mtb, gravel, bmx
+	Classification string       `json:"classification,omitempty"` // UCI class: 2.UWT, 1.Pro, C1
 	StartDate     string        `json:"start_date"`    // ISO 8601: 2026-02-04
-	EndDate       string        `json:"end_date"`      // ISO 8601: 2026-02-08
+	EndDate       string        `json:"end_date"`      // ISO 8601: 2026-02-08, last day inclusive
+	DatePrecision string        `json:"date_precision"` // day, month or unknown
 	Duration      string        `json:"duration"`      // e.g., "90 mins", "4 hrs"
 	AllDay        bool          `json:"all_day"`       // True if time is TBA/missing
 	Times         []TizTimeSlot `json:"times"`         // Multiple time slots (WE, ME)
+	UID           string        `json:"uid"`           // Stable across fetches and small title edits
+	Revision      Revision      `json:"revision"`      // SEQUENCE, CREATED and LAST-MODIFIED
 }
 
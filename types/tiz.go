@@ -1,37 +1,281 @@
 package types
 
+import (
+	"cpe/calendar/dates"
+	"fmt"
+	"strings"
+	"time"
+)
+
 // TizRace represents raw race data from Tiz endpoint
 type TizRace struct {
-	RawHTML       string
-	Country       string
-	CountryFlag   string
-	Name          string
-	Stage         string
-	Categories    []string
-	StreamType    string
-	StreamLinks   []string
-	StreamLang    string
-	Notes         string
-	StartDate     string
-	EndDate       string
-	Duration      string
-	Times         []TizTimeSlot
-	AllDay       bool
+	Source         string        `json:"source"` // Registry name of the source that produced the race
+	RawHTML        string        `json:"raw_html,omitempty"`
+	Country        string        `json:"country"`      // ISO 3166 alpha-2 code
+	CountryName    string        `json:"country_name"` // Short English name
+	CountryFlag    string        `json:"country_flag"`
+	Name           string        `json:"name"`
+	Stage          string        `json:"stage"`
+	Categories     []string      `json:"categories"`
+	Discipline     Discipline    `json:"discipline"`
+	Classification string        `json:"classification,omitempty"` // UCI class (2.UWT, 1.Pro, C1), empty when unknown
+	Status         string        `json:"status"`                   // CONFIRMED, TENTATIVE or CANCELLED
+	StatusNote     string        `json:"status_note,omitempty"`    // Cancelled, Postponed or Neutralised, as marked by the feed
+	StreamType     string        `json:"stream_type"`
+	StreamLinks    []string      `json:"stream_links"` // URLs of the Links the feed gives an address for
+	Links          []TizLink     `json:"links"`        // Stream page, direct streams and info pages in feed order
+	StreamLang     string        `json:"stream_lang"`
+	Notes          string        `json:"notes"`
+	StartDate      string        `json:"start_date"`     // First race day
+	EndDate        string        `json:"end_date"`       // Last race day, inclusive (StartDate for a one-day race)
+	DatePrecision  string        `json:"date_precision"` // day, month or unknown
+	Duration       string        `json:"duration"`
+	Times          []TizTimeSlot `json:"times"`
+	AllDay         bool          `json:"all_day"`
+	UID            string        `json:"-"` // Set when the race is served, not parsed
+	Revision       Revision      `json:"-"`
+}
+
+// TizLink is a link of a race as labelled by the feed
+type TizLink struct {
+	URL       string   `json:"url"`
+	Label     string   `json:"label,omitempty"`     // Stream Page, Link 2, Info, empty for a bare URL
+	Kind      string   `json:"kind"`                // stream, direct or info
+	Language  string   `json:"language,omitempty"`  // Commentary as written (Spanish, English or Spanish)
+	Languages []string `json:"languages,omitempty"` // BCP 47 tags of the commentary (es, en), empty when unknown
+}
+
+// Kinds of a link
+const (
+	LinkKindStream = "stream" // The Tiz stream page
+	LinkKindDirect = "direct" // A broadcast outside Tiz
+	LinkKindInfo   = "info"   // Race information, not a broadcast
+)
+
+// Watchable reports whether the link leads to a broadcast
+func (l TizLink) Watchable() bool {
+	return l.Kind == LinkKindStream || l.Kind == LinkKindDirect
+}
+
+// Languages returns the BCP 47 tags of the commentary of the broadcasts of
+// the race, without duplicates
+func (r TizRace) Languages() []string {
+	var tags []string
+	for _, link := range r.Links {
+		if !link.Watchable() {
+			continue
+		}
+		for _, tag := range link.Languages {
+			if !containsTag(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// containsTag tells whether tags holds tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Revision tracks the changes of a race as served to calendar clients
+type Revision struct {
+	Sequence     int       `json:"sequence"`      // Bumped whenever the start, end, title, stream info or cancellation changes
+	Created      time.Time `json:"created"`       // First time the race was served
+	LastModified time.Time `json:"last_modified"` // Last time Sequence was bumped
+}
+
+// Date precisions of a race
+const (
+	DatePrecisionDay     = "day"     // StartDate and EndDate are exact days
+	DatePrecisionMonth   = "month"   // Only the month is known, the dates span it
+	DatePrecisionUnknown = "unknown" // The feed gives no usable date (e.g. TBC)
+)
+
+// Statuses of a race, as iCalendar STATUS values (RFC 5545 3.8.1.11)
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE" // The broadcast is only possible or probable
+	StatusCancelled = "CANCELLED" // Cancelled or postponed
+)
+
+// Discipline is the kind of racing of a race
+type Discipline string
+
+// Disciplines of a race
+const (
+	DisciplineRoad       Discipline = "road"
+	DisciplineCyclocross Discipline = "cyclocross"
+	DisciplineTrack      Discipline = "track"
+	DisciplineMTB        Discipline = "mtb"
+	DisciplineGravel     Discipline = "gravel"
+	DisciplineBMX        Discipline = "bmx"
+)
+
+// DisciplineNames maps disciplines to display names
+var DisciplineNames = map[Discipline]string{
+	DisciplineRoad:       "Road",
+	DisciplineCyclocross: "Cyclocross",
+	DisciplineTrack:      "Track",
+	DisciplineMTB:        "Mountain Bike",
+	DisciplineGravel:     "Gravel",
+	DisciplineBMX:        "BMX",
+}
+
+// ParseDiscipline returns the discipline with the given name, in any case
+func ParseDiscipline(name string) (Discipline, bool) {
+	discipline := Discipline(strings.ToLower(strings.TrimSpace(name)))
+	_, ok := DisciplineNames[discipline]
+	return discipline, ok
+}
+
+// UCI classification tiers, as accepted by the calendar filter
+var ClassificationTiers = []string{"WT", "Pro", "1", "2", "CDM", "CM", "CC", "CN", "C1", "C2"}
+
+// ClassificationTier returns the tier of a UCI class: WT for 1.UWT or 2.WWT,
+// Pro for 2.Pro, 1 for 1.1, CDM for CDM. The boolean is false for classes
+// outside ClassificationTiers.
+func ClassificationTier(class string) (string, bool) {
+	tier := class
+	if kind, level, ok := strings.Cut(class, "."); ok {
+		if kind != "1" && kind != "2" {
+			return "", false
+		}
+		tier = level
+		if level == "UWT" || level == "WWT" {
+			tier = "WT"
+		}
+	}
+	return ParseClassificationTier(tier)
+}
+
+// ParseClassificationTier returns the tier with the given name, in any case
+// and with an optional leading dot (.1, .2)
+func ParseClassificationTier(name string) (string, bool) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), ".")
+	for _, tier := range ClassificationTiers {
+		if strings.EqualFold(tier, name) {
+			return tier, true
+		}
+	}
+	return "", false
 }
 
 type TizTimeSlot struct {
-	Category string  // WE, ME
-	Time     string  // 14:00 UTC
-	Duration string  // 60 mins
+	Category  string    `json:"category"`            // Code from TizCategoryMap (WE, ME, U23), empty if none
+	Label     string    `json:"label,omitempty"`     // Label as written (Men U23, Heats, Finals)
+	Time      string    `json:"time"`                // 14:00:00 UTC, once resolved
+	Zone      string    `json:"zone,omitempty"`      // Zone as written (UTC, CEST, UTC+2, local time), empty if none
+	Location  string    `json:"location,omitempty"`  // tz database location the time was resolved in
+	Start     time.Time `json:"start"`               // First occurrence, in Location
+	Ambiguity string    `json:"ambiguity,omitempty"` // Why the time may be wrong, empty when it is certain
+	Duration  string    `json:"duration"`            // 60 mins
+	Day       string    `json:"day,omitempty"`       // Days as written (stages 2-4, Sat)
+	Dates     []string  `json:"dates,omitempty"`     // ISO 8601 dates of the slot, empty for every day
+}
+
+// AppliesTo reports whether the slot is held on the given ISO 8601 date
+func (s TizTimeSlot) AppliesTo(date string) bool {
+	if s.Day == "" {
+		return true
+	}
+	for _, d := range s.Dates {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
+
+// On returns the time of the slot on the given ISO 8601 date, keeping its
+// wall clock time in its location across daylight saving changes
+func (s TizTimeSlot) On(date string) (time.Time, error) {
+	if s.Start.IsZero() {
+		return time.Time{}, fmt.Errorf("time slot %q is not resolved", s.Time)
+	}
+	day, err := dates.Parse(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc := s.Start.Location()
+	if s.Location != "" {
+		if named, err := time.LoadLocation(s.Location); err == nil {
+			loc = named
+		}
+	}
+	clock := s.Start.In(loc)
+
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc), nil
+}
+
+// FirstSlotOn returns the first time slot held on the given ISO 8601 date
+func FirstSlotOn(times []TizTimeSlot, date string) (TizTimeSlot, bool) {
+	for _, slot := range times {
+		if slot.AppliesTo(date) {
+			return slot, true
+		}
+	}
+	return TizTimeSlot{}, false
+}
+
+// FirstSlot returns the time slot a race starts with and the date it is
+// first held on: the first slot held on startDate, else the day-restricted
+// slot held earliest from startDate on, else the first slot
+func FirstSlot(times []TizTimeSlot, startDate string) (TizTimeSlot, string, bool) {
+	if slot, ok := FirstSlotOn(times, startDate); ok {
+		return slot, startDate, true
+	}
+
+	var first TizTimeSlot
+	firstDate := ""
+	for _, slot := range times {
+		for _, date := range slot.Dates {
+			if date >= startDate && (firstDate == "" || date < firstDate) {
+				first, firstDate = slot, date
+			}
+		}
+	}
+	if firstDate != "" {
+		return first, firstDate, true
+	}
+	if len(times) > 0 {
+		return times[0], startDate, true
+	}
+	return TizTimeSlot{}, "", false
 }
 
 // Map Tiz categories to display names
 var TizCategoryMap = map[string]string{
-	"WE":   "Women Elite",
-	"ME":   "Men Elite",
+	"WE":    "Women Elite",
+	"ME":    "Men Elite",
 	"track": "Track",
 	"MTB":   "Mountain Bike",
-	"NC":   "National Championships",
-	"JR":   "Junior",
-	"WC":   "World Championships",
+	"NC":    "National Championships",
+	"JR":    "Junior",
+	"U23":   "Under 23",
+	"WC":    "World Championships",
+}
+
+// tizCategoryNames are the categories the feed also writes in full
+var tizCategoryNames = []string{"Women Elite", "Men Elite", "Women", "Men", "Elite"}
+
+// IsTizCategory tells whether category is one the feed writes next to a
+// race: a code of TizCategoryMap or a name in full
+func IsTizCategory(category string) bool {
+	if _, ok := TizCategoryMap[category]; ok {
+		return true
+	}
+	for _, name := range tizCategoryNames {
+		if name == category {
+			return true
+		}
+	}
+	return false
 }